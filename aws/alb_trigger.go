@@ -0,0 +1,509 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	// albMultiValueHeadersAttribute makes the load balancer pass repeated
+	// headers and query parameters as lists
+	albMultiValueHeadersAttribute = "lambda.multi_value_headers.enabled"
+	// albTargetGroupNameMaxLength is the length limit of target group names
+	albTargetGroupNameMaxLength = 32
+)
+
+var (
+	targetGroupNameRegexp             = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,30}[a-zA-Z0-9])?$`)
+	invalidTargetGroupNameCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9-]`)
+)
+
+// albTargetGroupName returns the target group name of the event block,
+// derived from the function name when not set
+func albTargetGroupName(event map[string]interface{}, functionName string) string {
+	if v := event["target_group_name"].(string); v != "" {
+		return v
+	}
+	name := invalidTargetGroupNameCharsRegexp.ReplaceAllString(functionName, "-")
+	if len(name) > albTargetGroupNameMaxLength {
+		name = name[:albTargetGroupNameMaxLength]
+	}
+	return strings.Trim(name, "-")
+}
+
+// albStatementId returns the id of the permission allowing the target
+// group to invoke the function
+func albStatementId(targetGroupName string) string {
+	return "ALB_" + targetGroupName
+}
+
+// customizeDiffAlbTriggerDrift plans trigger_drift back to false when the
+// target group or the listener rule was deleted, so that the next apply
+// runs the update creating them again
+func customizeDiffAlbTriggerDrift(d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.Get("trigger_drift").(bool) {
+		return nil
+	}
+	return d.SetNew("trigger_drift", false)
+}
+
+// customizeDiffAlbConditions checks that the listener rule has a condition,
+// only the default rule of a listener matches every request
+func customizeDiffAlbConditions(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("event") {
+		return nil
+	}
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	if len(expandAlbRuleConditions(event)) == 0 {
+		return fmt.Errorf("event requires at least one of path_patterns, host_headers or http_header")
+	}
+	return nil
+}
+
+// expandAlbRuleConditions returns the listener rule conditions of the event block
+func expandAlbRuleConditions(event map[string]interface{}) []*elbv2.RuleCondition {
+	conditions := []*elbv2.RuleCondition{}
+	if v := event["path_patterns"].([]interface{}); len(v) > 0 {
+		conditions = append(conditions, &elbv2.RuleCondition{
+			Field:             aws.String("path-pattern"),
+			PathPatternConfig: &elbv2.PathPatternConditionConfig{Values: expandStringList(v)},
+		})
+	}
+	if v := event["host_headers"].([]interface{}); len(v) > 0 {
+		conditions = append(conditions, &elbv2.RuleCondition{
+			Field:            aws.String("host-header"),
+			HostHeaderConfig: &elbv2.HostHeaderConditionConfig{Values: expandStringList(v)},
+		})
+	}
+	for _, h := range event["http_header"].([]interface{}) {
+		header := h.(map[string]interface{})
+		conditions = append(conditions, &elbv2.RuleCondition{
+			Field: aws.String("http-header"),
+			HttpHeaderConfig: &elbv2.HttpHeaderConditionConfig{
+				HttpHeaderName: aws.String(header["http_header_name"].(string)),
+				Values:         expandStringList(header["values"].([]interface{})),
+			},
+		})
+	}
+	return conditions
+}
+
+// flattenAlbRuleConditions sets the conditions of the listener rule in the
+// event block
+func flattenAlbRuleConditions(conditions []*elbv2.RuleCondition, event map[string]interface{}) {
+	event["path_patterns"] = []interface{}{}
+	event["host_headers"] = []interface{}{}
+	headers := []interface{}{}
+	for _, condition := range conditions {
+		switch aws.StringValue(condition.Field) {
+		case "path-pattern":
+			if condition.PathPatternConfig != nil {
+				event["path_patterns"] = flattenStringList(condition.PathPatternConfig.Values)
+			}
+		case "host-header":
+			if condition.HostHeaderConfig != nil {
+				event["host_headers"] = flattenStringList(condition.HostHeaderConfig.Values)
+			}
+		case "http-header":
+			if condition.HttpHeaderConfig != nil {
+				headers = append(headers, map[string]interface{}{
+					"http_header_name": aws.StringValue(condition.HttpHeaderConfig.HttpHeaderName),
+					"values":           flattenStringList(condition.HttpHeaderConfig.Values),
+				})
+			}
+		}
+	}
+	event["http_header"] = headers
+}
+
+// albTrigger is the target group of the function and the listener rule
+// forwarding to it, the ARNs are set once they are created
+type albTrigger struct {
+	TargetGroupName   string
+	TargetGroupArn    string
+	ListenerArn       string
+	Priority          int
+	Conditions        []*elbv2.RuleCondition
+	MultiValueHeaders bool
+	FunctionArn       string
+	RuleArn           string
+}
+
+// expandAlbTrigger returns the target group and the listener rule of the
+// event block
+func expandAlbTrigger(d *schema.ResourceData, functionArn string) *albTrigger {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	return &albTrigger{
+		TargetGroupName:   albTargetGroupName(event, d.Get("function_name").(string)),
+		TargetGroupArn:    event["target_group_arn"].(string),
+		ListenerArn:       event["listener_arn"].(string),
+		Priority:          event["priority"].(int),
+		Conditions:        expandAlbRuleConditions(event),
+		MultiValueHeaders: event["multi_value_headers"].(bool),
+		FunctionArn:       functionArn,
+		RuleArn:           event["rule_arn"].(string),
+	}
+}
+
+func putAlbTargetGroupAttributes(conn *elbv2.ELBV2, t *albTrigger) error {
+	_, err := conn.ModifyTargetGroupAttributes(&elbv2.ModifyTargetGroupAttributesInput{
+		TargetGroupArn: aws.String(t.TargetGroupArn),
+		Attributes: []*elbv2.TargetGroupAttribute{
+			{
+				Key:   aws.String(albMultiValueHeadersAttribute),
+				Value: aws.String(strconv.FormatBool(t.MultiValueHeaders)),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Error setting attributes of target group %s: %s", t.TargetGroupName, err)
+	}
+	return nil
+}
+
+func createAlbRule(conn *elbv2.ELBV2, t *albTrigger) error {
+	input := &elbv2.CreateRuleInput{
+		ListenerArn: aws.String(t.ListenerArn),
+		Priority:    aws.Int64(int64(t.Priority)),
+		Conditions:  t.Conditions,
+		Actions: []*elbv2.Action{
+			{
+				Type:           aws.String(elbv2.ActionTypeEnumForward),
+				TargetGroupArn: aws.String(t.TargetGroupArn),
+			},
+		},
+	}
+
+	log.Printf("[DEBUG] Creating listener rule: %s", input)
+	out, err := conn.CreateRule(input)
+	if err != nil {
+		return fmt.Errorf("Error creating rule of listener %s: %s", t.ListenerArn, err)
+	}
+	t.RuleArn = aws.StringValue(out.Rules[0].RuleArn)
+	return nil
+}
+
+func deleteAlbRule(conn *elbv2.ELBV2, t *albTrigger) error {
+	if t.RuleArn == "" {
+		return nil
+	}
+	_, err := conn.DeleteRule(&elbv2.DeleteRuleInput{
+		RuleArn: aws.String(t.RuleArn),
+	})
+	if err != nil && !isAWSErr(err, elbv2.ErrCodeRuleNotFoundException, "") {
+		return fmt.Errorf("Error deleting listener rule %s: %s", t.RuleArn, err)
+	}
+	return nil
+}
+
+func deleteAlbTargetGroup(conn *elbv2.ELBV2, t *albTrigger) error {
+	if t.TargetGroupArn == "" {
+		return nil
+	}
+	_, err := conn.DeleteTargetGroup(&elbv2.DeleteTargetGroupInput{
+		TargetGroupArn: aws.String(t.TargetGroupArn),
+	})
+	if err != nil && !isAWSErr(err, elbv2.ErrCodeTargetGroupNotFoundException, "") {
+		return fmt.Errorf("Error deleting target group %s: %s", t.TargetGroupName, err)
+	}
+	return nil
+}
+
+// albTargetGroup is the lambda target group of the trigger
+type albTargetGroup struct {
+	Trigger *albTrigger
+}
+
+// Create creates the target group and sets its attributes
+func (r *albTargetGroup) Create() error {
+	conn := elbv2.New(auth.Sess)
+	out, err := conn.CreateTargetGroup(&elbv2.CreateTargetGroupInput{
+		Name:       aws.String(r.Trigger.TargetGroupName),
+		TargetType: aws.String(elbv2.TargetTypeEnumLambda),
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating target group %s: %s", r.Trigger.TargetGroupName, err)
+	}
+	r.Trigger.TargetGroupArn = aws.StringValue(out.TargetGroups[0].TargetGroupArn)
+	return putAlbTargetGroupAttributes(conn, r.Trigger)
+}
+
+// Delete deletes the target group
+func (r *albTargetGroup) Delete() error {
+	return deleteAlbTargetGroup(elbv2.New(auth.Sess), r.Trigger)
+}
+
+// albPermission allows the target group to invoke the function, its source
+// is only known once the target group is created
+type albPermission struct {
+	Trigger *albTrigger
+}
+
+func (r *albPermission) permission() *functionPermission {
+	return &functionPermission{
+		StatementId:  albStatementId(r.Trigger.TargetGroupName),
+		FunctionName: r.Trigger.FunctionArn,
+		SourceArn:    r.Trigger.TargetGroupArn,
+		Principal:    "elasticloadbalancing.amazonaws.com",
+		Action:       "lambda:InvokeFunction",
+	}
+}
+
+// Create adds the permission
+func (r *albPermission) Create() error {
+	return r.permission().Create()
+}
+
+// Delete removes the permission
+func (r *albPermission) Delete() error {
+	return r.permission().Delete()
+}
+
+// albTarget registers the function in the target group
+type albTarget struct {
+	Trigger *albTrigger
+}
+
+// Create registers the function
+func (r *albTarget) Create() error {
+	_, err := elbv2.New(auth.Sess).RegisterTargets(&elbv2.RegisterTargetsInput{
+		TargetGroupArn: aws.String(r.Trigger.TargetGroupArn),
+		Targets:        []*elbv2.TargetDescription{{Id: aws.String(r.Trigger.FunctionArn)}},
+	})
+	if err != nil {
+		return fmt.Errorf("Error registering function in target group %s: %s", r.Trigger.TargetGroupName, err)
+	}
+	return nil
+}
+
+// Delete deregisters the function
+func (r *albTarget) Delete() error {
+	_, err := elbv2.New(auth.Sess).DeregisterTargets(&elbv2.DeregisterTargetsInput{
+		TargetGroupArn: aws.String(r.Trigger.TargetGroupArn),
+		Targets:        []*elbv2.TargetDescription{{Id: aws.String(r.Trigger.FunctionArn)}},
+	})
+	return err
+}
+
+// albRule is the listener rule forwarding to the target group
+type albRule struct {
+	Trigger *albTrigger
+}
+
+// Create creates the listener rule
+func (r *albRule) Create() error {
+	return createAlbRule(elbv2.New(auth.Sess), r.Trigger)
+}
+
+// Delete deletes the listener rule
+func (r *albRule) Delete() error {
+	return deleteAlbRule(elbv2.New(auth.Sess), r.Trigger)
+}
+
+// albCreateFunctionInput creates the function served by the load balancer
+// listener of the event block
+type albCreateFunctionInput struct {
+	FunctionInput *lambda.CreateFunctionInput
+	Data          *schema.ResourceData
+}
+
+// CreateDependencies creates the target group, allows it to invoke the
+// function, registers the function and forwards the listener rule to it
+func (input albCreateFunctionInput) CreateDependencies(lambdaResult *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	trigger := expandAlbTrigger(input.Data, aws.StringValue(lambdaResult.FunctionArn))
+	err := executeCreate(
+		&albTargetGroup{Trigger: trigger},
+		&albPermission{Trigger: trigger},
+		&albTarget{Trigger: trigger},
+		&albRule{Trigger: trigger},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	out["TargetGroupName"] = trigger.TargetGroupName
+	out["TargetGroupArn"] = trigger.TargetGroupArn
+	out["RuleArn"] = trigger.RuleArn
+	return out, nil
+}
+
+// GetFunctionInput returns the CreateFunctionInput of the function
+func (input albCreateFunctionInput) GetFunctionInput() *lambda.CreateFunctionInput {
+	return input.FunctionInput
+}
+
+// albTriggerTarget returns the ARN registered in the target group, the
+// alias when triggers are bound to it
+func albTriggerTarget(d *schema.ResourceData) string {
+	if d.Get("alias.0.bind_triggers").(bool) {
+		return d.Get("alias.0.arn").(string)
+	}
+	return d.Get("arn").(string)
+}
+
+// recreateAlbTargetGroup creates the target group again after it was
+// deleted, with the permission and the registration of the function. The
+// rule forwarding to the deleted group is replaced.
+func recreateAlbTargetGroup(conn *elbv2.ELBV2, t *albTrigger) error {
+	if err := deleteAlbRule(conn, t); err != nil {
+		return err
+	}
+	t.RuleArn = ""
+
+	if err := (&albTargetGroup{Trigger: t}).Create(); err != nil {
+		return err
+	}
+
+	permission := &albPermission{Trigger: t}
+	if err := permission.Delete(); err != nil && !isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") {
+		return fmt.Errorf("Error removing permission of target group %s: %s", t.TargetGroupName, err)
+	}
+	if err := permission.Create(); err != nil {
+		return fmt.Errorf("Error adding permission of target group %s: %s", t.TargetGroupName, err)
+	}
+
+	return (&albTarget{Trigger: t}).Create()
+}
+
+// updateAlbTrigger applies the changes of the event block to the target
+// group and the listener rule, recreating the target group and the rule if
+// they were deleted
+func updateAlbTrigger(conn *elbv2.ELBV2, d *schema.ResourceData) error {
+	if !d.HasChange("event") && !d.HasChange("trigger_drift") {
+		return nil
+	}
+	trigger := expandAlbTrigger(d, albTriggerTarget(d))
+
+	if trigger.TargetGroupArn == "" {
+		if err := recreateAlbTargetGroup(conn, trigger); err != nil {
+			return err
+		}
+	} else if d.HasChange("event.0.multi_value_headers") {
+		if err := putAlbTargetGroupAttributes(conn, trigger); err != nil {
+			return err
+		}
+	}
+
+	if trigger.RuleArn == "" {
+		if err := createAlbRule(conn, trigger); err != nil {
+			return err
+		}
+		event := d.Get("event").([]interface{})[0].(map[string]interface{})
+		event["target_group_arn"] = trigger.TargetGroupArn
+		event["rule_arn"] = trigger.RuleArn
+		return d.Set("event", []interface{}{event})
+	}
+
+	if d.HasChange("event.0.priority") {
+		_, err := conn.SetRulePriorities(&elbv2.SetRulePrioritiesInput{
+			RulePriorities: []*elbv2.RulePriorityPair{
+				{
+					RuleArn:  aws.String(trigger.RuleArn),
+					Priority: aws.Int64(int64(trigger.Priority)),
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Error setting priority of listener rule %s: %s", trigger.RuleArn, err)
+		}
+	}
+
+	if d.HasChange("event.0.path_patterns") || d.HasChange("event.0.host_headers") || d.HasChange("event.0.http_header") {
+		_, err := conn.ModifyRule(&elbv2.ModifyRuleInput{
+			RuleArn:    aws.String(trigger.RuleArn),
+			Conditions: trigger.Conditions,
+		})
+		if err != nil {
+			return fmt.Errorf("Error modifying listener rule %s: %s", trigger.RuleArn, err)
+		}
+	}
+	return nil
+}
+
+// readAlbTrigger refreshes the event block from the target group and the
+// listener rule. A deleted target group clears target_group_arn and a
+// deleted rule clears rule_arn, both set trigger_drift so that the next
+// apply creates them again.
+func readAlbTrigger(conn *elbv2.ELBV2, d *schema.ResourceData) error {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	trigger := expandAlbTrigger(d, albTriggerTarget(d))
+
+	if trigger.TargetGroupArn != "" {
+		attributes, err := conn.DescribeTargetGroupAttributes(&elbv2.DescribeTargetGroupAttributesInput{
+			TargetGroupArn: aws.String(trigger.TargetGroupArn),
+		})
+		if err != nil && !isAWSErr(err, elbv2.ErrCodeTargetGroupNotFoundException, "") {
+			return fmt.Errorf("Error reading attributes of target group %s: %s", trigger.TargetGroupName, err)
+		}
+		if err != nil {
+			log.Printf("[WARN] Target group %s not found", trigger.TargetGroupName)
+			trigger.TargetGroupArn = ""
+		} else {
+			for _, attribute := range attributes.Attributes {
+				if aws.StringValue(attribute.Key) == albMultiValueHeadersAttribute {
+					event["multi_value_headers"] = aws.StringValue(attribute.Value) == "true"
+				}
+			}
+		}
+	}
+	event["target_group_arn"] = trigger.TargetGroupArn
+
+	var rule *elbv2.Rule
+	if trigger.RuleArn != "" && trigger.TargetGroupArn != "" {
+		out, err := conn.DescribeRules(&elbv2.DescribeRulesInput{
+			RuleArns: []*string{aws.String(trigger.RuleArn)},
+		})
+		if err != nil && !isAWSErr(err, elbv2.ErrCodeRuleNotFoundException, "") {
+			return fmt.Errorf("Error reading listener rule %s: %s", trigger.RuleArn, err)
+		}
+		if err == nil && len(out.Rules) > 0 {
+			rule = out.Rules[0]
+		}
+	}
+
+	if rule == nil {
+		log.Printf("[WARN] Listener rule %s not found", trigger.RuleArn)
+		event["rule_arn"] = ""
+	} else {
+		priority, _ := strconv.Atoi(aws.StringValue(rule.Priority))
+		event["priority"] = priority
+		flattenAlbRuleConditions(rule.Conditions, event)
+	}
+	event["target_group_name"] = trigger.TargetGroupName
+	event["statement_id"] = albStatementId(trigger.TargetGroupName)
+
+	if err := d.Set("event", []interface{}{event}); err != nil {
+		return fmt.Errorf("Error setting event: %s", err)
+	}
+	d.Set("trigger_drift", rule == nil)
+	return nil
+}
+
+// deleteAlbTrigger deletes the listener rule, deregisters the function and
+// deletes the target group
+func deleteAlbTrigger(conn *elbv2.ELBV2, d *schema.ResourceData) error {
+	trigger := expandAlbTrigger(d, albTriggerTarget(d))
+
+	if err := deleteAlbRule(conn, trigger); err != nil {
+		return err
+	}
+
+	if trigger.TargetGroupArn != "" {
+		err := (&albTarget{Trigger: trigger}).Delete()
+		if err != nil && !isAWSErr(err, elbv2.ErrCodeTargetGroupNotFoundException, "") {
+			return fmt.Errorf("Error deregistering function from target group %s: %s", trigger.TargetGroupName, err)
+		}
+	}
+
+	return deleteAlbTargetGroup(conn, trigger)
+}
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAlbTargetGroupName(t *testing.T) {
+	testCases := []struct {
+		Configured string
+		Function   string
+		Expected   string
+	}{
+		{Configured: "orders", Function: "OrdersFunction", Expected: "orders"},
+		{Function: "OrdersFunction", Expected: "OrdersFunction"},
+		{Function: "orders_api_function", Expected: "orders-api-function"},
+		{Function: "a_very_long_function_name_for_the_orders_api", Expected: "a-very-long-function-name-for-th"},
+		{Function: "_private_", Expected: "private"},
+	}
+
+	for _, testCase := range testCases {
+		event := map[string]interface{}{"target_group_name": testCase.Configured}
+		name := albTargetGroupName(event, testCase.Function)
+		if name != testCase.Expected {
+			t.Errorf("%s: expected %s, got %s", testCase.Function, testCase.Expected, name)
+		}
+		if !targetGroupNameRegexp.MatchString(name) {
+			t.Errorf("%s: %s is not a valid target group name", testCase.Function, name)
+		}
+	}
+}
+
+func TestAlbRuleConditions(t *testing.T) {
+	event := map[string]interface{}{
+		"path_patterns": []interface{}{"/orders/*"},
+		"host_headers":  []interface{}{"api.example.com"},
+		"http_header": []interface{}{
+			map[string]interface{}{
+				"http_header_name": "X-Version",
+				"values":           []interface{}{"2"},
+			},
+		},
+	}
+
+	conditions := expandAlbRuleConditions(event)
+	if len(conditions) != 3 {
+		t.Fatalf("expected 3 conditions, got %d", len(conditions))
+	}
+
+	flattened := map[string]interface{}{}
+	flattenAlbRuleConditions(conditions, flattened)
+	if !reflect.DeepEqual(flattened, event) {
+		t.Errorf("expected %v, got %v", event, flattened)
+	}
+
+	empty := map[string]interface{}{
+		"path_patterns": []interface{}{},
+		"host_headers":  []interface{}{},
+		"http_header":   []interface{}{},
+	}
+	if conditions := expandAlbRuleConditions(empty); len(conditions) != 0 {
+		t.Errorf("expected no conditions, got %v", conditions)
+	}
+}
+
+func TestCustomizeDiffAlbTriggerDrift(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		State    *terraform.InstanceState
+		Expected bool
+	}{
+		{Name: "new resource", State: nil, Expected: false},
+		{
+			Name:     "in sync",
+			State:    &terraform.InstanceState{ID: "TestFunction", Attributes: map[string]string{"trigger_drift": "false"}},
+			Expected: false,
+		},
+		{
+			Name:     "rule deleted",
+			State:    &terraform.InstanceState{ID: "TestFunction", Attributes: map[string]string{"trigger_drift": "true"}},
+			Expected: true,
+		},
+	}
+
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"trigger_drift": {Type: schema.TypeBool, Computed: true},
+		},
+		CustomizeDiff: customizeDiffAlbTriggerDrift,
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			diff, err := r.Diff(testCase.State, terraform.NewResourceConfigRaw(map[string]interface{}{}), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			updated := false
+			if diff != nil {
+				attribute, ok := diff.GetAttribute("trigger_drift")
+				updated = ok && attribute.Old == "true" && attribute.New == "false"
+			}
+			if updated != testCase.Expected {
+				t.Errorf("expected update %t, got %t", testCase.Expected, updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// aliasSchema is the alias managed by the function resources
+func aliasSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Required: true,
+					ForceNew: true,
+				},
+				"description": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"function_version": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"routing_config": {
+					Type:     schema.TypeList,
+					Optional: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"additional_version_weights": {
+								Type:     schema.TypeMap,
+								Optional: true,
+								Elem:     &schema.Schema{Type: schema.TypeFloat},
+							},
+						},
+					},
+				},
+				"bind_triggers": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+					ForceNew: true,
+				},
+				"current_version": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// expandAliasRoutingConfig returns the routing config of the alias block
+func expandAliasRoutingConfig(alias map[string]interface{}) *lambda.AliasRoutingConfiguration {
+	routing := &lambda.AliasRoutingConfiguration{
+		AdditionalVersionWeights: map[string]*float64{},
+	}
+
+	configs := alias["routing_config"].([]interface{})
+	if len(configs) == 0 || configs[0] == nil {
+		return routing
+	}
+
+	weights := configs[0].(map[string]interface{})["additional_version_weights"].(map[string]interface{})
+	for version, weight := range weights {
+		routing.AdditionalVersionWeights[version] = aws.Float64(weight.(float64))
+	}
+	return routing
+}
+
+// aliasTargetVersion returns the version the alias must point to: the
+// configured function_version, else the latest version of the function
+func aliasTargetVersion(d *schema.ResourceData, alias map[string]interface{}) string {
+	if v := alias["function_version"].(string); v != "" {
+		return v
+	}
+	if v := d.Get("version").(string); v != "" {
+		return v
+	}
+	return "$LATEST"
+}
+
+// createFunctionAlias creates the configured alias, if any, and returns its ARN
+func createFunctionAlias(conn *lambda.Lambda, d *schema.ResourceData) (string, error) {
+	v, ok := d.GetOk("alias")
+	if !ok {
+		return "", nil
+	}
+	alias := v.([]interface{})[0].(map[string]interface{})
+
+	input := &lambda.CreateAliasInput{
+		FunctionName:    aws.String(d.Get("function_name").(string)),
+		Name:            aws.String(alias["name"].(string)),
+		Description:     aws.String(alias["description"].(string)),
+		FunctionVersion: aws.String(aliasTargetVersion(d, alias)),
+		RoutingConfig:   expandAliasRoutingConfig(alias),
+	}
+
+	log.Printf("[DEBUG] Creating Lambda alias %s on version %s", *input.Name, *input.FunctionVersion)
+	out, err := conn.CreateAlias(input)
+	if err != nil {
+		return "", fmt.Errorf("Error creating Lambda alias %s: %s", *input.Name, err)
+	}
+	return aws.StringValue(out.AliasArn), nil
+}
+
+// updateFunctionAlias moves the alias to its target version and updates the
+// routing configuration, gradually when a deployment_preference is set
+func updateFunctionAlias(conn *lambda.Lambda, d *schema.ResourceData) error {
+	v, ok := d.GetOk("alias")
+	if !ok {
+		return nil
+	}
+	alias := v.([]interface{})[0].(map[string]interface{})
+
+	target := aliasTargetVersion(d, alias)
+	current := alias["current_version"].(string)
+	if !d.HasChange("alias") && !d.HasChange("version") && current == target {
+		return nil
+	}
+
+	input := &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(d.Id()),
+		Name:            aws.String(alias["name"].(string)),
+		Description:     aws.String(alias["description"].(string)),
+		FunctionVersion: aws.String(target),
+		RoutingConfig:   expandAliasRoutingConfig(alias),
+	}
+
+	if _, ok := d.GetOk("deployment_preference"); ok && current != target && current != "" && current != "$LATEST" && target != "$LATEST" {
+		return shiftFunctionAlias(conn, d, input, current)
+	}
+
+	log.Printf("[DEBUG] Updating Lambda alias %s to version %s", *input.Name, target)
+	if _, err := conn.UpdateAlias(input); err != nil {
+		return fmt.Errorf("Error updating Lambda alias %s: %s", *input.Name, err)
+	}
+	return nil
+}
+
+// readFunctionAlias refreshes the alias block, keeping the configured
+// function_version so that an unset version does not cause a diff
+func readFunctionAlias(conn *lambda.Lambda, d *schema.ResourceData) error {
+	v, ok := d.GetOk("alias")
+	if !ok {
+		return nil
+	}
+	alias := v.([]interface{})[0].(map[string]interface{})
+
+	out, err := conn.GetAlias(&lambda.GetAliasInput{
+		FunctionName: aws.String(d.Id()),
+		Name:         aws.String(alias["name"].(string)),
+	})
+	if err != nil {
+		if isAWSErr(err, "ResourceNotFoundException", "") {
+			log.Printf("[WARN] Lambda alias %s not found, removing from state", alias["name"].(string))
+			return d.Set("alias", nil)
+		}
+		return fmt.Errorf("Error reading Lambda alias %s: %s", alias["name"].(string), err)
+	}
+
+	routingConfig := []interface{}{}
+	if out.RoutingConfig != nil && len(out.RoutingConfig.AdditionalVersionWeights) > 0 {
+		weights := make(map[string]interface{})
+		for version, weight := range out.RoutingConfig.AdditionalVersionWeights {
+			weights[version] = aws.Float64Value(weight)
+		}
+		routingConfig = append(routingConfig, map[string]interface{}{
+			"additional_version_weights": weights,
+		})
+	}
+
+	return d.Set("alias", []interface{}{
+		map[string]interface{}{
+			"name":             aws.StringValue(out.Name),
+			"description":      aws.StringValue(out.Description),
+			"function_version": alias["function_version"],
+			"routing_config":   routingConfig,
+			"bind_triggers":    alias["bind_triggers"],
+			"current_version":  aws.StringValue(out.FunctionVersion),
+			"arn":              aws.StringValue(out.AliasArn),
+		},
+	})
+}
+
+// readLatestFunctionVersion returns the latest published version of the
+// function, or $LATEST if no version has been published
+func readLatestFunctionVersion(conn *lambda.Lambda, functionName string) (string, error) {
+	latest := -1
+	err := conn.ListVersionsByFunctionPages(&lambda.ListVersionsByFunctionInput{
+		FunctionName: aws.String(functionName),
+	}, func(page *lambda.ListVersionsByFunctionOutput, lastPage bool) bool {
+		for _, version := range page.Versions {
+			if n, err := strconv.Atoi(aws.StringValue(version.Version)); err == nil && n > latest {
+				latest = n
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error listing versions of Lambda function %s: %s", functionName, err)
+	}
+
+	if latest < 0 {
+		return "$LATEST", nil
+	}
+	return strconv.Itoa(latest), nil
+}
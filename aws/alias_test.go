@@ -0,0 +1,29 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestExpandAliasRoutingConfig(t *testing.T) {
+	routing := expandAliasRoutingConfig(map[string]interface{}{
+		"routing_config": []interface{}{},
+	})
+	if len(routing.AdditionalVersionWeights) != 0 {
+		t.Errorf("expected no weights, got %v", routing.AdditionalVersionWeights)
+	}
+
+	routing = expandAliasRoutingConfig(map[string]interface{}{
+		"routing_config": []interface{}{
+			map[string]interface{}{
+				"additional_version_weights": map[string]interface{}{
+					"2": 0.1,
+				},
+			},
+		},
+	})
+	if got := aws.Float64Value(routing.AdditionalVersionWeights["2"]); got != 0.1 {
+		t.Errorf("bad weight for version 2: %v", got)
+	}
+}
@@ -0,0 +1,202 @@
+package aws
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// zipModTime is the timestamp stored for every entry of the archives built
+// by the provider, so that the same sources always produce the same zip
+var zipModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// zipEntry is a file to be stored inside a deployment package
+type zipEntry struct {
+	Name string
+	Path string
+	Mode os.FileMode
+}
+
+// buildSourceZip builds a deterministic deployment package with the files of
+// dir matching the include patterns (all files if empty) and none of the
+// exclude patterns
+func buildSourceZip(dir string, includes, excludes []string) ([]byte, error) {
+	root, err := homedir.Expand(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []zipEntry
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if len(includes) > 0 && !matchAnySourceGlob(includes, rel) {
+			return nil
+		}
+		if matchAnySourceGlob(excludes, rel) {
+			return nil
+		}
+
+		entries = append(entries, zipEntry{Name: rel, Path: p, Mode: info.Mode()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading source_dir %q: %s", dir, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("No files found in source_dir %q", dir)
+	}
+
+	return writeZip(entries)
+}
+
+// writeZip writes the given entries sorted by name, with fixed timestamps and
+// normalized permissions that only preserve the executable bit
+func writeZip(entries []zipEntry) ([]byte, error) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.Name,
+			Method:   zip.Deflate,
+			Modified: zipModTime,
+		}
+		if entry.Mode&0111 != 0 {
+			header.SetMode(0755)
+		} else {
+			header.SetMode(0644)
+		}
+
+		content, err := ioutil.ReadFile(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := w.CreateHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// sourceCodeHash returns the base64 encoded SHA-256 of a deployment package,
+// the same format used by Lambda for CodeSha256
+func sourceCodeHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// fileSourceCodeHash returns the source code hash of a deployment package on
+// disk, the file is streamed instead of being loaded into memory
+func fileSourceCodeHash(filename string) (string, error) {
+	p, err := homedir.Expand(filename)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// matchAnySourceGlob reports whether name matches at least one of the patterns
+func matchAnySourceGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchSourceGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSourceGlob matches a slash separated relative path against a glob
+// pattern. "*" and "?" do not match "/", while "**" matches any number of
+// directories. A pattern matching a directory matches every file below it,
+// and patterns without "/" are matched against each path element, so that
+// "*.pyc" or "node_modules" apply at every level.
+func matchSourceGlob(pattern, name string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+	re := sourceGlobRegexp(pattern)
+	anchored := strings.Contains(pattern, "/")
+
+	elements := strings.Split(name, "/")
+	for i := range elements {
+		candidate := elements[i]
+		if anchored {
+			candidate = path.Join(elements[:i+1]...)
+		}
+		if re.MatchString(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func sourceGlobRegexp(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					expr.WriteString("(?:.*/)?")
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
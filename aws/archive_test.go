@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatchSourceGlob(t *testing.T) {
+	testCases := []struct {
+		Pattern  string
+		Name     string
+		Expected bool
+	}{
+		{Pattern: "*.py", Name: "main.py", Expected: true},
+		{Pattern: "*.py", Name: "lib/util.py", Expected: true},
+		{Pattern: "*.py", Name: "main.pyc", Expected: false},
+		{Pattern: "node_modules", Name: "node_modules/left-pad/index.js", Expected: true},
+		{Pattern: "node_modules/", Name: "lib/node_modules/left-pad/index.js", Expected: true},
+		{Pattern: "lib/*.js", Name: "lib/index.js", Expected: true},
+		{Pattern: "lib/*.js", Name: "lib/nested/index.js", Expected: false},
+		{Pattern: "lib/**/*.js", Name: "lib/index.js", Expected: true},
+		{Pattern: "lib/**/*.js", Name: "lib/nested/deep/index.js", Expected: true},
+		{Pattern: "./tests/**", Name: "tests/unit/main_test.py", Expected: true},
+		{Pattern: "tests/**", Name: "src/tests/main_test.py", Expected: false},
+		{Pattern: "?.txt", Name: "a.txt", Expected: true},
+		{Pattern: "?.txt", Name: "ab.txt", Expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Pattern+" "+testCase.Name, func(t *testing.T) {
+			if got := matchSourceGlob(testCase.Pattern, testCase.Name); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildSourceZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source_dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]os.FileMode{
+		"main.py":           0644,
+		"bootstrap":         0755,
+		"lib/util.py":       0600,
+		"lib/util.pyc":      0644,
+		"tests/test_foo.py": 0644,
+	}
+	for name, mode := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := buildSourceZip(dir, nil, []string{"*.pyc", "tests"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Touching the files must not change the archive
+	later := time.Now().Add(time.Hour)
+	for name := range files {
+		os.Chtimes(filepath.Join(dir, filepath.FromSlash(name)), later, later)
+	}
+	second, err := buildSourceZip(dir, nil, []string{"*.pyc", "tests"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatal("archive is not deterministic")
+	}
+	if sourceCodeHash(first) != sourceCodeHash(second) {
+		t.Fatal("source code hash is not deterministic")
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	modes := make(map[string]os.FileMode)
+	for _, f := range r.File {
+		names = append(names, f.Name)
+		modes[f.Name] = f.Mode().Perm()
+		if !f.Modified.Equal(zipModTime) {
+			t.Errorf("%s: bad modification time %s", f.Name, f.Modified)
+		}
+	}
+
+	expected := []string{"bootstrap", "lib/util.py", "main.py"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("bad entries: %v, expected %v", names, expected)
+	}
+	if modes["bootstrap"] != 0755 {
+		t.Errorf("executable bit not preserved: %s", modes["bootstrap"])
+	}
+	if modes["lib/util.py"] != 0644 {
+		t.Errorf("bad normalized mode: %s", modes["lib/util.py"])
+	}
+
+	if _, err := buildSourceZip(dir, []string{"*.go"}, nil); err == nil {
+		t.Fatal("expected error for empty archive")
+	}
+}
+
+func TestFileSourceCodeHash(t *testing.T) {
+	f, err := ioutil.TempFile("", "package*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	content := []byte("deployment package")
+	if _, err := f.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	hash, err := fileSourceCodeHash(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if hash != sourceCodeHash(content) {
+		t.Errorf("got %q, expected %q", hash, sourceCodeHash(content))
+	}
+
+	if _, err := fileSourceCodeHash(f.Name() + ".missing"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// lambdaDirectUploadLimit is the largest deployment package uploaded inline,
+// larger packages are staged to the artifact bucket
+const lambdaDirectUploadLimit = 50 * 1024 * 1024
+
+// functionCodeFromFile returns the deployment package stored in filename.
+// Small packages are loaded into memory while holding an upload slot, large
+// ones are streamed to the artifact bucket.
+func (c *Config) functionCodeFromFile(filename string) (*lambda.FunctionCode, func(), error) {
+	p, err := homedir.Expand(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to load %q: %s", filename, err)
+	}
+
+	if info.Size() > lambdaDirectUploadLimit {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to load %q: %s", filename, err)
+		}
+		defer f.Close()
+
+		functionCode, err := c.stageArtifact(f, info.Size())
+		if err != nil {
+			return nil, nil, err
+		}
+		return functionCode, func() {}, nil
+	}
+
+	release := c.acquireUpload()
+	file, err := loadFileContent(filename)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("Unable to load %q: %s", filename, err)
+	}
+	return &lambda.FunctionCode{
+		ZipFile: file,
+	}, release, nil
+}
+
+// functionCodeFromZip returns a deployment package built in memory by build.
+// The upload slot is acquired before the build so that it bounds the packages
+// held in memory, staged packages release it once uploaded.
+func (c *Config) functionCodeFromZip(build func() ([]byte, error)) (*lambda.FunctionCode, func(), error) {
+	release := c.acquireUpload()
+	content, err := build()
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	if len(content) > lambdaDirectUploadLimit {
+		defer release()
+		functionCode, err := c.stageArtifact(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, nil, err
+		}
+		return functionCode, func() {}, nil
+	}
+
+	return &lambda.FunctionCode{
+		ZipFile: content,
+	}, release, nil
+}
+
+// artifactKey returns the content addressed key of a staged package
+func (c *Config) artifactKey(hash string) string {
+	return c.ArtifactPrefix + hash + ".zip"
+}
+
+// stageArtifact uploads the package to the artifact bucket with a multipart
+// upload, packages already staged are not uploaded again
+func (c *Config) stageArtifact(body io.ReadSeeker, size int64) (*lambda.FunctionCode, error) {
+	if c == nil || c.ArtifactBucket == "" {
+		return nil, fmt.Errorf("Deployment package of %d bytes exceeds the direct upload limit of %d bytes, artifact_bucket must be set in the provider configuration", size, lambdaDirectUploadLimit)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return nil, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	key := c.artifactKey(hex.EncodeToString(h.Sum(nil)))
+
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.S3Conn
+
+	functionCode := &lambda.FunctionCode{
+		S3Bucket: aws.String(c.ArtifactBucket),
+		S3Key:    aws.String(key),
+	}
+
+	_, err := conn.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(c.ArtifactBucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		log.Printf("[DEBUG] Deployment package already staged to s3://%s/%s", c.ArtifactBucket, key)
+		return functionCode, nil
+	}
+	if !isAWSErr(err, "NotFound", "") {
+		return nil, fmt.Errorf("Error reading staged deployment package s3://%s/%s: %s", c.ArtifactBucket, key, err)
+	}
+
+	log.Printf("[DEBUG] Staging deployment package of %d bytes to s3://%s/%s", size, c.ArtifactBucket, key)
+	uploader := s3manager.NewUploaderWithClient(conn)
+	_, err = uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(c.ArtifactBucket),
+		Key:    aws.String(key),
+		Body:   body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error staging deployment package to s3://%s/%s: %s", c.ArtifactBucket, key, err)
+	}
+
+	return functionCode, nil
+}
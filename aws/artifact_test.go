@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFunctionCodeFromZip(t *testing.T) {
+	config := &Config{
+		uploads: make(chan struct{}, 1),
+	}
+
+	content := []byte("deployment package")
+	functionCode, release, err := config.functionCodeFromZip(func() ([]byte, error) {
+		if len(config.uploads) != 1 {
+			t.Errorf("upload slot not held while building")
+		}
+		return content, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(functionCode.ZipFile, content) {
+		t.Errorf("bad zip file: %q", functionCode.ZipFile)
+	}
+	if len(config.uploads) != 1 {
+		t.Fatalf("upload slot not held")
+	}
+	release()
+	release()
+	if len(config.uploads) != 0 {
+		t.Fatalf("upload slot not released")
+	}
+
+	large := make([]byte, lambdaDirectUploadLimit+1)
+	if _, _, err := config.functionCodeFromZip(func() ([]byte, error) { return large, nil }); err == nil {
+		t.Fatal("expected error staging without artifact_bucket")
+	}
+	if len(config.uploads) != 0 {
+		t.Fatalf("upload slot held by staged package")
+	}
+}
+
+func TestConfigArtifactKey(t *testing.T) {
+	config := &Config{
+		ArtifactPrefix: "serverless-artifacts/",
+	}
+	if got := config.artifactKey("abc"); got != "serverless-artifacts/abc.zip" {
+		t.Errorf("bad key: %q", got)
+	}
+}
@@ -0,0 +1,193 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// validAsyncDestinationServices are the services an asynchronous invocation
+// record can be sent to
+var validAsyncDestinationServices = []string{
+	"sqs",
+	"sns",
+	"lambda",
+	"events",
+}
+
+// asyncConfigSchema is the asynchronous invocation configuration of the function
+func asyncConfigSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"maximum_retry_attempts": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      2,
+					ValidateFunc: validation.IntBetween(0, 2),
+				},
+				"maximum_event_age_in_seconds": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      21600,
+					ValidateFunc: validation.IntBetween(60, 21600),
+				},
+				"on_success": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateAsyncDestinationArn,
+				},
+				"on_failure": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateAsyncDestinationArn,
+				},
+			},
+		},
+	}
+}
+
+// validateAsyncDestinationArn checks that the destination is the ARN of an
+// SQS queue, SNS topic, Lambda function or EventBridge event bus
+func validateAsyncDestinationArn(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+
+	parsed, err := arn.Parse(value)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q is not a valid ARN: %s", k, err))
+		return
+	}
+
+	for _, service := range validAsyncDestinationServices {
+		if parsed.Service == service {
+			return
+		}
+	}
+	errors = append(errors, fmt.Errorf("%q must be an SQS, SNS, Lambda or EventBridge ARN, got %q", k, value))
+	return
+}
+
+// asyncConfigQualifier returns the qualifier the triggers invoke, the
+// configuration of an unqualified function does not apply to its aliases
+func asyncConfigQualifier(d *schema.ResourceData) *string {
+	if v, ok := d.GetOk("alias.0.bind_triggers"); ok && v.(bool) {
+		return aws.String(d.Get("alias.0.name").(string))
+	}
+	return nil
+}
+
+// expandAsyncDestinationConfig returns the destinations of the async_config block
+func expandAsyncDestinationConfig(config map[string]interface{}) *lambda.DestinationConfig {
+	destinations := &lambda.DestinationConfig{
+		OnSuccess: &lambda.OnSuccess{},
+		OnFailure: &lambda.OnFailure{},
+	}
+	if v := config["on_success"].(string); v != "" {
+		destinations.OnSuccess.Destination = aws.String(v)
+	}
+	if v := config["on_failure"].(string); v != "" {
+		destinations.OnFailure.Destination = aws.String(v)
+	}
+	return destinations
+}
+
+// putFunctionAsyncConfig applies the async_config block, replacing the
+// previous configuration
+func putFunctionAsyncConfig(conn *lambda.Lambda, d *schema.ResourceData, functionName string) error {
+	config := d.Get("async_config").([]interface{})[0].(map[string]interface{})
+
+	input := &lambda.PutFunctionEventInvokeConfigInput{
+		FunctionName:             aws.String(functionName),
+		Qualifier:                asyncConfigQualifier(d),
+		MaximumRetryAttempts:     aws.Int64(int64(config["maximum_retry_attempts"].(int))),
+		MaximumEventAgeInSeconds: aws.Int64(int64(config["maximum_event_age_in_seconds"].(int))),
+		DestinationConfig:        expandAsyncDestinationConfig(config),
+	}
+
+	log.Printf("[DEBUG] Putting asynchronous invocation config of Lambda function %s: %s", functionName, input)
+	if _, err := conn.PutFunctionEventInvokeConfig(input); err != nil {
+		return fmt.Errorf("Error putting asynchronous invocation config of Lambda function %s: %s", functionName, err)
+	}
+	return nil
+}
+
+// createFunctionAsyncConfig applies the async_config block of a new function
+func createFunctionAsyncConfig(conn *lambda.Lambda, d *schema.ResourceData) error {
+	if _, ok := d.GetOk("async_config"); !ok {
+		return nil
+	}
+	return putFunctionAsyncConfig(conn, d, d.Get("function_name").(string))
+}
+
+// updateFunctionAsyncConfig puts the changed async_config block, or deletes
+// the configuration when the block is removed
+func updateFunctionAsyncConfig(conn *lambda.Lambda, d *schema.ResourceData) error {
+	if !d.HasChange("async_config") {
+		return nil
+	}
+
+	if _, ok := d.GetOk("async_config"); ok {
+		return putFunctionAsyncConfig(conn, d, d.Id())
+	}
+
+	log.Printf("[DEBUG] Deleting asynchronous invocation config of Lambda function %s", d.Id())
+	_, err := conn.DeleteFunctionEventInvokeConfig(&lambda.DeleteFunctionEventInvokeConfigInput{
+		FunctionName: aws.String(d.Id()),
+		Qualifier:    asyncConfigQualifier(d),
+	})
+	if err != nil && !isAWSErr(err, "ResourceNotFoundException", "") {
+		return fmt.Errorf("Error deleting asynchronous invocation config of Lambda function %s: %s", d.Id(), err)
+	}
+	return nil
+}
+
+// readFunctionAsyncConfig refreshes the async_config block
+func readFunctionAsyncConfig(conn *lambda.Lambda, d *schema.ResourceData) error {
+	out, err := conn.GetFunctionEventInvokeConfig(&lambda.GetFunctionEventInvokeConfigInput{
+		FunctionName: aws.String(d.Id()),
+		Qualifier:    asyncConfigQualifier(d),
+	})
+	if err != nil {
+		if isAWSErr(err, "ResourceNotFoundException", "") {
+			return d.Set("async_config", nil)
+		}
+		return fmt.Errorf("Error reading asynchronous invocation config of Lambda function %s: %s", d.Id(), err)
+	}
+
+	config := map[string]interface{}{
+		"maximum_retry_attempts":       int(aws.Int64Value(out.MaximumRetryAttempts)),
+		"maximum_event_age_in_seconds": int(aws.Int64Value(out.MaximumEventAgeInSeconds)),
+		"on_success":                   "",
+		"on_failure":                   "",
+	}
+	if out.MaximumRetryAttempts == nil {
+		config["maximum_retry_attempts"] = 2
+	}
+	if out.MaximumEventAgeInSeconds == nil {
+		config["maximum_event_age_in_seconds"] = 21600
+	}
+	if dest := out.DestinationConfig; dest != nil {
+		if dest.OnSuccess != nil {
+			config["on_success"] = aws.StringValue(dest.OnSuccess.Destination)
+		}
+		if dest.OnFailure != nil {
+			config["on_failure"] = aws.StringValue(dest.OnFailure.Destination)
+		}
+	}
+
+	if err := d.Set("async_config", []interface{}{config}); err != nil {
+		return fmt.Errorf("Error setting async_config: %s", err)
+	}
+	return nil
+}
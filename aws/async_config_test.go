@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestValidateAsyncDestinationArn(t *testing.T) {
+	testCases := []struct {
+		Value string
+		Error bool
+	}{
+		{Value: ""},
+		{Value: "arn:aws:sqs:eu-west-1:123456789012:failures"},
+		{Value: "arn:aws:sns:eu-west-1:123456789012:notifications"},
+		{Value: "arn:aws:lambda:eu-west-1:123456789012:function:handler"},
+		{Value: "arn:aws:events:eu-west-1:123456789012:event-bus/default"},
+		{Value: "arn:aws:s3:::bucket", Error: true},
+		{Value: "failures", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Value, func(t *testing.T) {
+			_, errors := validateAsyncDestinationArn(testCase.Value, "on_failure")
+			if testCase.Error && len(errors) == 0 {
+				t.Fatal("expected error")
+			}
+			if !testCase.Error && len(errors) > 0 {
+				t.Fatalf("unexpected errors: %v", errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+var validBuildLanguages = []string{
+	"go",
+}
+
+// goBuildConfig is the configuration of a build block with language "go"
+type goBuildConfig struct {
+	Package    string
+	WorkingDir string
+	GOOS       string
+	GOARCH     string
+	LDFlags    string
+	Tags       []string
+	Handler    string
+}
+
+// expandGoBuildConfig reads the build block of the function
+func expandGoBuildConfig(d resourceConfig) goBuildConfig {
+	build := d.Get("build").([]interface{})[0].(map[string]interface{})
+	return goBuildConfig{
+		Package:    build["package"].(string),
+		WorkingDir: build["working_dir"].(string),
+		GOOS:       build["goos"].(string),
+		GOARCH:     build["goarch"].(string),
+		LDFlags:    build["ldflags"].(string),
+		Tags:       expandStringValueList(build["tags"].([]interface{})),
+		Handler:    d.Get("handler").(string),
+	}
+}
+
+// args returns the go build arguments, -trimpath and an empty build id keep
+// the binary reproducible so that source_code_hash only changes with the sources
+func (c goBuildConfig) args(output string) []string {
+	args := []string{"build", "-trimpath", "-o", output}
+	args = append(args, "-ldflags", strings.TrimSpace("-buildid= "+c.LDFlags))
+	if len(c.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(c.Tags, ","))
+	}
+	return append(args, c.Package)
+}
+
+// env returns the environment used to cross compile the handler
+func (c goBuildConfig) env() []string {
+	return append(os.Environ(),
+		"GOOS="+c.GOOS,
+		"GOARCH="+c.GOARCH,
+		"CGO_ENABLED=0",
+	)
+}
+
+// command returns a go command running in the configured working directory
+func (c goBuildConfig) command(args ...string) (*exec.Cmd, error) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return nil, fmt.Errorf("Go toolchain not found, required by the build block: %s", err)
+	}
+
+	cmd := exec.Command(goBin, args...)
+	cmd.Env = c.env()
+	if c.WorkingDir != "" {
+		dir, err := homedir.Expand(c.WorkingDir)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Dir = dir
+	}
+	return cmd, nil
+}
+
+// goListPackage is the subset of go list -json used to hash the sources
+type goListPackage struct {
+	Dir        string
+	Standard   bool
+	GoFiles    []string
+	EmbedFiles []string
+	Module     *struct {
+		GoMod string
+	}
+}
+
+// sourceHash returns the cache key of the build: the toolchain version, the
+// build configuration and the content of every non standard source file
+// the package depends on
+func (c goBuildConfig) sourceHash() (string, error) {
+	h := sha256.New()
+
+	version, err := c.command("version")
+	if err != nil {
+		return "", err
+	}
+	out, err := version.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error running go version: %s", err)
+	}
+	h.Write(out)
+	fmt.Fprintf(h, "%q\n%q\n", c.args("handler"), []string{c.GOOS, c.GOARCH, c.Handler})
+
+	listArgs := []string{"list", "-deps", "-json"}
+	if len(c.Tags) > 0 {
+		listArgs = append(listArgs, "-tags", strings.Join(c.Tags, ","))
+	}
+	list, err := c.command(append(listArgs, c.Package)...)
+	if err != nil {
+		return "", err
+	}
+	var stderr bytes.Buffer
+	list.Stderr = &stderr
+	out, err = list.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error listing go package %s: %s\n%s", c.Package, err, stderr.String())
+	}
+
+	var files []string
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var pkg goListPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return "", fmt.Errorf("Error decoding go list output: %s", err)
+		}
+		if pkg.Standard {
+			continue
+		}
+		for _, f := range append(pkg.GoFiles, pkg.EmbedFiles...) {
+			files = append(files, filepath.Join(pkg.Dir, f))
+		}
+		if pkg.Module != nil && pkg.Module.GoMod != "" {
+			files = append(files, pkg.Module.GoMod, strings.TrimSuffix(pkg.Module.GoMod, ".mod")+".sum")
+		}
+	}
+
+	sort.Strings(files)
+	visited := make(map[string]bool)
+	for _, file := range files {
+		if visited[file] {
+			continue
+		}
+		visited[file] = true
+
+		f, err := os.Open(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\n", file)
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// goBuildCacheDir is where the built deployment packages are cached
+func goBuildCacheDir() string {
+	return filepath.Join(os.TempDir(), "terraform-provider-serverless", "go-build")
+}
+
+// buildGoArtifact cross compiles the package and zips the binary under the
+// handler name, packages are cached by source hash
+func buildGoArtifact(c goBuildConfig) ([]byte, error) {
+	key, err := c.sourceHash()
+	if err != nil {
+		return nil, err
+	}
+
+	cached := filepath.Join(goBuildCacheDir(), key+".zip")
+	if content, err := ioutil.ReadFile(cached); err == nil {
+		log.Printf("[DEBUG] Using cached build of %s: %s", c.Package, cached)
+		return content, nil
+	}
+
+	tmp, err := ioutil.TempDir("", "go-build")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	binary := filepath.Join(tmp, "handler")
+	build, err := c.command(c.args(binary)...)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Building go package %s: go %s", c.Package, strings.Join(build.Args[1:], " "))
+	if out, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("Error building go package %s: %s\n%s", c.Package, err, out)
+	}
+
+	content, err := writeZip([]zipEntry{
+		{Name: c.Handler, Path: binary, Mode: 0755},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeGoBuildCache(cached, content); err != nil {
+		log.Printf("[WARN] Unable to cache build of %s: %s", c.Package, err)
+	}
+	return content, nil
+}
+
+// writeGoBuildCache stores the package atomically, concurrent builds of the
+// same sources produce the same content
+func writeGoBuildCache(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "partial")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGoBuildConfigArgs(t *testing.T) {
+	c := goBuildConfig{
+		Package: "./cmd/handler",
+		LDFlags: "-s -w",
+		Tags:    []string{"lambda", "netgo"},
+	}
+
+	expected := []string{
+		"build", "-trimpath", "-o", "out",
+		"-ldflags", "-buildid= -s -w",
+		"-tags", "lambda,netgo",
+		"./cmd/handler",
+	}
+	if got := c.args("out"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got %q, expected %q", got, expected)
+	}
+
+	c = goBuildConfig{Package: "."}
+	expected = []string{"build", "-trimpath", "-o", "out", "-ldflags", "-buildid=", "."}
+	if got := c.args("out"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestBuildGoArtifact(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir, err := ioutil.TempDir("", "go-handler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":  "module example.com/handler\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := goBuildConfig{
+		Package:    ".",
+		WorkingDir: dir,
+		GOOS:       "linux",
+		GOARCH:     "amd64",
+		Handler:    "main",
+	}
+
+	key, err := c.sourceHash()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(filepath.Join(goBuildCacheDir(), key+".zip"))
+
+	first, err := buildGoArtifact(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	second, err := buildGoArtifact(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatal("cached package differs from the built one")
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.File) != 1 || r.File[0].Name != "main" {
+		t.Fatalf("bad entries: %v", r.File)
+	}
+	if r.File[0].Mode().Perm() != 0755 {
+		t.Errorf("handler is not executable: %s", r.File[0].Mode())
+	}
+
+	// Changing the sources changes the cache key
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() { println() }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := c.sourceHash()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if changed == key {
+		t.Error("source hash did not change with the sources")
+	}
+}
@@ -0,0 +1,277 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// logsSubscriptionPropagationTimeout is how long CloudWatch Logs may take to
+// see the permission allowing it to invoke the function
+const logsSubscriptionPropagationTimeout = 2 * time.Minute
+
+// cloudWatchLogsStatementId returns the id of the permission allowing the
+// log group to invoke the function
+func cloudWatchLogsStatementId(logGroupName string) string {
+	id := "CloudWatchLogs_" + invalidStatementIdCharsRegexp.ReplaceAllString(logGroupName, "_")
+	if len(id) > 100 {
+		id = id[:100]
+	}
+	return id
+}
+
+// logGroupArn returns the ARN of a log group in the account and region of
+// the function, as matched by the invoke permission
+func logGroupArn(functionArn, logGroupName string) (string, error) {
+	parts := strings.Split(functionArn, ":")
+	if len(parts) < 7 || parts[2] != "lambda" {
+		return "", fmt.Errorf("invalid function ARN %q", functionArn)
+	}
+	return fmt.Sprintf("arn:%s:logs:%s:%s:log-group:%s:*", parts[1], parts[3], parts[4], logGroupName), nil
+}
+
+// customizeDiffCloudWatchLogsTrigger rejects subscriptions to the log group
+// of the function itself, every invocation would trigger the next one
+func customizeDiffCloudWatchLogsTrigger(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("event") || !d.NewValueKnown("function_name") {
+		return nil
+	}
+	name := d.Get("event.0.log_group_name").(string)
+	if name == functionLogGroupName(d.Get("function_name").(string)) {
+		return fmt.Errorf("log_group_name %s is the log group of the function, subscribing to it would invoke the function in a loop", name)
+	}
+	return nil
+}
+
+// logsSubscription is the subscription filter of the log group invoking the
+// function
+type logsSubscription struct {
+	LogGroupName   string
+	FilterName     string
+	FilterPattern  string
+	DestinationArn string
+}
+
+// expandLogsSubscription returns the subscription filter of the event block
+func expandLogsSubscription(event map[string]interface{}, functionName, destinationArn string) *logsSubscription {
+	filterName := event["filter_name"].(string)
+	if filterName == "" {
+		filterName = functionName
+	}
+	return &logsSubscription{
+		LogGroupName:   event["log_group_name"].(string),
+		FilterName:     filterName,
+		FilterPattern:  event["filter_pattern"].(string),
+		DestinationArn: destinationArn,
+	}
+}
+
+// Create puts the subscription filter, retrying until the invoke
+// permission is visible to CloudWatch Logs
+func (s *logsSubscription) Create() error {
+	return putLogsSubscription(cloudwatchlogs.New(auth.Sess), s)
+}
+
+// Delete removes the subscription filter
+func (s *logsSubscription) Delete() error {
+	return deleteLogsSubscription(cloudwatchlogs.New(auth.Sess), s)
+}
+
+func putLogsSubscription(conn *cloudwatchlogs.CloudWatchLogs, s *logsSubscription) error {
+	input := &cloudwatchlogs.PutSubscriptionFilterInput{
+		LogGroupName:   aws.String(s.LogGroupName),
+		FilterName:     aws.String(s.FilterName),
+		FilterPattern:  aws.String(s.FilterPattern),
+		DestinationArn: aws.String(s.DestinationArn),
+	}
+
+	log.Printf("[DEBUG] Putting subscription filter of log group %s: %s", s.LogGroupName, input)
+	err := resource.Retry(logsSubscriptionPropagationTimeout, func() *resource.RetryError {
+		_, err := conn.PutSubscriptionFilter(input)
+		if isAWSErr(err, cloudwatchlogs.ErrCodeInvalidParameterException, "Could not execute the lambda function") {
+			log.Printf("[DEBUG] Received %s, retrying PutSubscriptionFilter", err)
+			return resource.RetryableError(err)
+		}
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Error putting subscription filter %s of log group %s: %s", s.FilterName, s.LogGroupName, err)
+	}
+	return nil
+}
+
+func deleteLogsSubscription(conn *cloudwatchlogs.CloudWatchLogs, s *logsSubscription) error {
+	_, err := conn.DeleteSubscriptionFilter(&cloudwatchlogs.DeleteSubscriptionFilterInput{
+		LogGroupName: aws.String(s.LogGroupName),
+		FilterName:   aws.String(s.FilterName),
+	})
+	if err != nil && !isAWSErr(err, cloudwatchlogs.ErrCodeResourceNotFoundException, "") {
+		return fmt.Errorf("Error deleting subscription filter %s of log group %s: %s", s.FilterName, s.LogGroupName, err)
+	}
+	return nil
+}
+
+// cloudWatchLogsCreateFunctionInput creates the function invoked by the
+// subscription filter of the event block
+type cloudWatchLogsCreateFunctionInput struct {
+	FunctionInput *lambda.CreateFunctionInput
+	Event         map[string]interface{}
+}
+
+// CreateDependencies allows the log group to invoke the function and
+// subscribes the function to the log group
+func (input cloudWatchLogsCreateFunctionInput) CreateDependencies(lambdaResult *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	functionArn := aws.StringValue(lambdaResult.FunctionArn)
+	subscription := expandLogsSubscription(input.Event, aws.StringValue(lambdaResult.FunctionName), functionArn)
+	sourceArn, err := logGroupArn(functionArn, subscription.LogGroupName)
+	if err != nil {
+		return nil, err
+	}
+	account, err := functionArnAccount(functionArn)
+	if err != nil {
+		return nil, err
+	}
+
+	permission := functionPermission{
+		StatementId:   cloudWatchLogsStatementId(subscription.LogGroupName),
+		FunctionName:  functionArn,
+		SourceArn:     sourceArn,
+		SourceAccount: account,
+		Principal:     "logs.amazonaws.com",
+		Action:        "lambda:InvokeFunction",
+	}
+	err = executeCreate(&permission, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	out["FilterName"] = subscription.FilterName
+	out["StatementId"] = permission.StatementId
+	return out, nil
+}
+
+// GetFunctionInput returns the CreateFunctionInput of the function
+func (input cloudWatchLogsCreateFunctionInput) GetFunctionInput() *lambda.CreateFunctionInput {
+	return input.FunctionInput
+}
+
+// cloudWatchLogsTriggerTarget returns the ARN invoked by the subscription,
+// the alias when triggers are bound to it
+func cloudWatchLogsTriggerTarget(d *schema.ResourceData) string {
+	if d.Get("alias.0.bind_triggers").(bool) {
+		return d.Get("alias.0.arn").(string)
+	}
+	return d.Get("arn").(string)
+}
+
+// updateCloudWatchLogsTrigger applies the changes of the event block. The
+// filter pattern is updated in place, a new log group or filter name moves
+// the permission and the subscription.
+func updateCloudWatchLogsTrigger(conn *cloudwatchlogs.CloudWatchLogs, lambdaConn *lambda.Lambda, d *schema.ResourceData) error {
+	if !d.HasChange("event") {
+		return nil
+	}
+	functionName := d.Get("function_name").(string)
+	target := cloudWatchLogsTriggerTarget(d)
+	o, n := d.GetChange("event")
+	old := expandLogsSubscription(o.([]interface{})[0].(map[string]interface{}), functionName, target)
+	subscription := expandLogsSubscription(n.([]interface{})[0].(map[string]interface{}), functionName, target)
+
+	if old.LogGroupName != subscription.LogGroupName || old.FilterName != subscription.FilterName {
+		if old.LogGroupName != "" {
+			if err := deleteLogsSubscription(conn, old); err != nil {
+				return err
+			}
+		}
+	}
+
+	if old.LogGroupName != subscription.LogGroupName {
+		if old.LogGroupName != "" {
+			_, err := lambdaConn.RemovePermission(&lambda.RemovePermissionInput{
+				FunctionName: aws.String(target),
+				StatementId:  aws.String(cloudWatchLogsStatementId(old.LogGroupName)),
+			})
+			if err != nil && !isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") {
+				return fmt.Errorf("Error removing permission of log group %s: %s", old.LogGroupName, err)
+			}
+		}
+
+		sourceArn, err := logGroupArn(target, subscription.LogGroupName)
+		if err != nil {
+			return err
+		}
+		account, err := functionArnAccount(target)
+		if err != nil {
+			return err
+		}
+
+		permission := functionPermission{
+			StatementId:   cloudWatchLogsStatementId(subscription.LogGroupName),
+			FunctionName:  target,
+			SourceArn:     sourceArn,
+			SourceAccount: account,
+			Principal:     "logs.amazonaws.com",
+			Action:        "lambda:InvokeFunction",
+		}
+		if err := permission.Create(); err != nil && !isAWSErr(err, lambda.ErrCodeResourceConflictException, "") {
+			return fmt.Errorf("Error adding permission of log group %s: %s", subscription.LogGroupName, err)
+		}
+	}
+
+	return putLogsSubscription(conn, subscription)
+}
+
+// readCloudWatchLogsTrigger refreshes the event block from the subscription
+// filter. A missing filter clears log_group_name so that the next apply
+// subscribes the function again.
+func readCloudWatchLogsTrigger(conn *cloudwatchlogs.CloudWatchLogs, d *schema.ResourceData) error {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	subscription := expandLogsSubscription(event, d.Get("function_name").(string), cloudWatchLogsTriggerTarget(d))
+
+	out, err := conn.DescribeSubscriptionFilters(&cloudwatchlogs.DescribeSubscriptionFiltersInput{
+		LogGroupName:     aws.String(subscription.LogGroupName),
+		FilterNamePrefix: aws.String(subscription.FilterName),
+	})
+	if err != nil && !isAWSErr(err, cloudwatchlogs.ErrCodeResourceNotFoundException, "") {
+		return fmt.Errorf("Error reading subscription filters of log group %s: %s", subscription.LogGroupName, err)
+	}
+
+	var filter *cloudwatchlogs.SubscriptionFilter
+	if out != nil {
+		for _, f := range out.SubscriptionFilters {
+			if aws.StringValue(f.FilterName) == subscription.FilterName {
+				filter = f
+			}
+		}
+	}
+
+	if filter == nil {
+		log.Printf("[WARN] Subscription filter %s of log group %s not found", subscription.FilterName, subscription.LogGroupName)
+		event["log_group_name"] = ""
+	} else {
+		event["filter_pattern"] = aws.StringValue(filter.FilterPattern)
+	}
+	sourceArn, err := logGroupArn(d.Get("arn").(string), subscription.LogGroupName)
+	if err != nil {
+		return err
+	}
+	event["log_group_arn"] = sourceArn
+	event["statement_id"] = cloudWatchLogsStatementId(subscription.LogGroupName)
+
+	if err := d.Set("event", []interface{}{event}); err != nil {
+		return fmt.Errorf("Error setting event: %s", err)
+	}
+	return nil
+}
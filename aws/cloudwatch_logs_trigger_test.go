@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestLogGroupArn(t *testing.T) {
+	testCases := []struct {
+		FunctionArn string
+		Expected    string
+		Error       bool
+	}{
+		{
+			FunctionArn: "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction:live",
+			Expected:    "arn:aws:logs:eu-west-1:123456789012:log-group:/aws/apigateway/access:*",
+		},
+		{FunctionArn: "", Error: true},
+		{FunctionArn: "TestFunction", Error: true},
+		{FunctionArn: "arn:aws:sqs:eu-west-1:123456789012:queue", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		got, err := logGroupArn(testCase.FunctionArn, "/aws/apigateway/access")
+		if testCase.Error != (err != nil) {
+			t.Errorf("%q: expected error %t, got %v", testCase.FunctionArn, testCase.Error, err)
+		}
+		if got != testCase.Expected {
+			t.Errorf("%q: expected %s, got %s", testCase.FunctionArn, testCase.Expected, got)
+		}
+	}
+}
+
+func TestCloudWatchLogsStatementId(t *testing.T) {
+	if got := cloudWatchLogsStatementId("/aws/apigateway/access.log"); got != "CloudWatchLogs__aws_apigateway_access_log" {
+		t.Errorf("bad statement id: %s", got)
+	}
+}
+
+func TestExpandLogsSubscription(t *testing.T) {
+	testCases := []struct {
+		FilterName string
+		Expected   string
+	}{
+		{FilterName: "", Expected: "TestFunction"},
+		{FilterName: "errors", Expected: "errors"},
+	}
+
+	for _, testCase := range testCases {
+		event := map[string]interface{}{
+			"log_group_name": "/aws/apigateway/access",
+			"filter_name":    testCase.FilterName,
+			"filter_pattern": "ERROR",
+		}
+		subscription := expandLogsSubscription(event, "TestFunction", "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction")
+		if subscription.FilterName != testCase.Expected {
+			t.Errorf("%q: expected filter name %s, got %s", testCase.FilterName, testCase.Expected, subscription.FilterName)
+		}
+		if subscription.FilterPattern != "ERROR" {
+			t.Errorf("%q: bad filter pattern %s", testCase.FilterName, subscription.FilterPattern)
+		}
+	}
+}
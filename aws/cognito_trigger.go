@@ -0,0 +1,316 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// cognitoLambdaConfigFields maps the trigger types to their field of the
+// user pool LambdaConfig. The custom sender triggers need a KMS key and are
+// not supported.
+var cognitoLambdaConfigFields = map[string]func(*cognitoidentityprovider.LambdaConfigType) **string{
+	"PreSignUp":                   func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.PreSignUp },
+	"CustomMessage":               func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.CustomMessage },
+	"PostConfirmation":            func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.PostConfirmation },
+	"PreAuthentication":           func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.PreAuthentication },
+	"PostAuthentication":          func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.PostAuthentication },
+	"DefineAuthChallenge":         func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.DefineAuthChallenge },
+	"CreateAuthChallenge":         func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.CreateAuthChallenge },
+	"VerifyAuthChallengeResponse": func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.VerifyAuthChallengeResponse },
+	"PreTokenGeneration":          func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.PreTokenGeneration },
+	"UserMigration":               func(c *cognitoidentityprovider.LambdaConfigType) **string { return &c.UserMigration },
+}
+
+// cognitoTriggerTypes returns the supported trigger types
+func cognitoTriggerTypes() []string {
+	types := make([]string, 0, len(cognitoLambdaConfigFields))
+	for t := range cognitoLambdaConfigFields {
+		types = append(types, t)
+	}
+	return types
+}
+
+// cognitoStatementId returns the id of the permission allowing the user
+// pool to invoke the function
+func cognitoStatementId(userPoolId string) string {
+	return "CognitoUserPool_" + invalidStatementIdCharsRegexp.ReplaceAllString(userPoolId, "_")
+}
+
+// userPoolArn returns the ARN of a user pool in the account and region of
+// the function, as matched by the invoke permission
+func userPoolArn(functionArn, userPoolId string) (string, error) {
+	parts := strings.Split(functionArn, ":")
+	if len(parts) < 7 || parts[2] != "lambda" {
+		return "", fmt.Errorf("invalid function ARN %q", functionArn)
+	}
+	return fmt.Sprintf("arn:%s:cognito-idp:%s:%s:userpool/%s", parts[1], parts[3], parts[4], userPoolId), nil
+}
+
+// cognitoUserPoolLockKey serializes the changes to the LambdaConfig of a
+// user pool, shared by every function it triggers
+func cognitoUserPoolLockKey(userPoolId string) string {
+	return "cognito-user-pool-" + userPoolId
+}
+
+// updateUserPoolInput returns an UpdateUserPoolInput keeping the current
+// settings of the pool, UpdateUserPool resets every omitted attribute to its
+// default
+func updateUserPoolInput(pool *cognitoidentityprovider.UserPoolType) *cognitoidentityprovider.UpdateUserPoolInput {
+	input := &cognitoidentityprovider.UpdateUserPoolInput{
+		UserPoolId:                  pool.Id,
+		AccountRecoverySetting:      pool.AccountRecoverySetting,
+		AdminCreateUserConfig:       pool.AdminCreateUserConfig,
+		AutoVerifiedAttributes:      pool.AutoVerifiedAttributes,
+		DeviceConfiguration:         pool.DeviceConfiguration,
+		EmailConfiguration:          pool.EmailConfiguration,
+		EmailVerificationMessage:    pool.EmailVerificationMessage,
+		EmailVerificationSubject:    pool.EmailVerificationSubject,
+		LambdaConfig:                pool.LambdaConfig,
+		MfaConfiguration:            pool.MfaConfiguration,
+		Policies:                    pool.Policies,
+		SmsAuthenticationMessage:    pool.SmsAuthenticationMessage,
+		SmsConfiguration:            pool.SmsConfiguration,
+		SmsVerificationMessage:      pool.SmsVerificationMessage,
+		UserPoolAddOns:              pool.UserPoolAddOns,
+		UserPoolTags:                pool.UserPoolTags,
+		VerificationMessageTemplate: pool.VerificationMessageTemplate,
+	}
+	if input.LambdaConfig == nil {
+		input.LambdaConfig = &cognitoidentityprovider.LambdaConfigType{}
+	}
+
+	// The deprecated UnusedAccountValidityDays is still returned but can't be
+	// sent along with the password policy replacing it
+	if input.AdminCreateUserConfig != nil && input.Policies != nil && input.Policies.PasswordPolicy != nil &&
+		input.Policies.PasswordPolicy.TemporaryPasswordValidityDays != nil {
+		config := *input.AdminCreateUserConfig
+		config.UnusedAccountValidityDays = nil
+		input.AdminCreateUserConfig = &config
+	}
+	return input
+}
+
+// cognitoTrigger is the trigger of the user pool invoking the function
+type cognitoTrigger struct {
+	UserPoolId  string
+	TriggerType string
+	FunctionArn string
+}
+
+// expandCognitoTrigger returns the user pool trigger of the event block
+func expandCognitoTrigger(event map[string]interface{}, functionArn string) *cognitoTrigger {
+	return &cognitoTrigger{
+		UserPoolId:  event["user_pool_id"].(string),
+		TriggerType: event["trigger_type"].(string),
+		FunctionArn: functionArn,
+	}
+}
+
+// Create sets the trigger in the LambdaConfig of the user pool
+func (t *cognitoTrigger) Create() error {
+	return putCognitoTrigger(cognitoidentityprovider.New(auth.Sess), t, true)
+}
+
+// Delete clears the trigger from the LambdaConfig of the user pool
+func (t *cognitoTrigger) Delete() error {
+	return putCognitoTrigger(cognitoidentityprovider.New(auth.Sess), t, false)
+}
+
+// putCognitoTrigger sets or clears the trigger in the LambdaConfig of the
+// user pool, leaving the other triggers and settings untouched. A trigger
+// invoking another function is never replaced nor cleared.
+func putCognitoTrigger(conn *cognitoidentityprovider.CognitoIdentityProvider, t *cognitoTrigger, attach bool) error {
+	field, ok := cognitoLambdaConfigFields[t.TriggerType]
+	if !ok {
+		return fmt.Errorf("Unsupported trigger type %s of user pool %s", t.TriggerType, t.UserPoolId)
+	}
+
+	lockKey := cognitoUserPoolLockKey(t.UserPoolId)
+	awsMutexKV.Lock(lockKey)
+	defer awsMutexKV.Unlock(lockKey)
+
+	out, err := conn.DescribeUserPool(&cognitoidentityprovider.DescribeUserPoolInput{
+		UserPoolId: aws.String(t.UserPoolId),
+	})
+	if err != nil {
+		if !attach && isAWSErr(err, cognitoidentityprovider.ErrCodeResourceNotFoundException, "") {
+			return nil
+		}
+		return fmt.Errorf("Error reading user pool %s: %s", t.UserPoolId, err)
+	}
+
+	input := updateUserPoolInput(out.UserPool)
+	current := aws.StringValue(*field(input.LambdaConfig))
+	owned := current != "" && unqualifiedFunctionArn(current) == unqualifiedFunctionArn(t.FunctionArn)
+
+	if attach {
+		if current != "" && !owned {
+			return fmt.Errorf("Trigger %s of user pool %s already invokes %s", t.TriggerType, t.UserPoolId, current)
+		}
+		if current == t.FunctionArn {
+			return nil
+		}
+		*field(input.LambdaConfig) = aws.String(t.FunctionArn)
+	} else {
+		if !owned {
+			return nil
+		}
+		*field(input.LambdaConfig) = nil
+	}
+
+	log.Printf("[DEBUG] Updating LambdaConfig of user pool %s: %s", t.UserPoolId, input.LambdaConfig)
+	if _, err := conn.UpdateUserPool(input); err != nil {
+		return fmt.Errorf("Error updating trigger %s of user pool %s: %s", t.TriggerType, t.UserPoolId, err)
+	}
+	return nil
+}
+
+// cognitoCreateFunctionInput creates the function invoked by the user pool
+// trigger of the event block
+type cognitoCreateFunctionInput struct {
+	FunctionInput *lambda.CreateFunctionInput
+	Event         map[string]interface{}
+}
+
+// CreateDependencies allows the user pool to invoke the function and sets
+// the trigger of the pool
+func (input cognitoCreateFunctionInput) CreateDependencies(lambdaResult *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	functionArn := aws.StringValue(lambdaResult.FunctionArn)
+	trigger := expandCognitoTrigger(input.Event, functionArn)
+	sourceArn, err := userPoolArn(functionArn, trigger.UserPoolId)
+	if err != nil {
+		return nil, err
+	}
+	account, err := functionArnAccount(functionArn)
+	if err != nil {
+		return nil, err
+	}
+
+	permission := functionPermission{
+		StatementId:   cognitoStatementId(trigger.UserPoolId),
+		FunctionName:  functionArn,
+		SourceArn:     sourceArn,
+		SourceAccount: account,
+		Principal:     "cognito-idp.amazonaws.com",
+		Action:        "lambda:InvokeFunction",
+	}
+	err = executeCreate(&permission, trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	out["StatementId"] = permission.StatementId
+	return out, nil
+}
+
+// GetFunctionInput returns the CreateFunctionInput of the function
+func (input cognitoCreateFunctionInput) GetFunctionInput() *lambda.CreateFunctionInput {
+	return input.FunctionInput
+}
+
+// cognitoTriggerTarget returns the ARN invoked by the user pool, the alias
+// when triggers are bound to it
+func cognitoTriggerTarget(d *schema.ResourceData) string {
+	if d.Get("alias.0.bind_triggers").(bool) {
+		return d.Get("alias.0.arn").(string)
+	}
+	return d.Get("arn").(string)
+}
+
+// updateCognitoTrigger applies the changes of the event block. A new
+// trigger type clears the previous one, a new user pool also moves the
+// permission.
+func updateCognitoTrigger(conn *cognitoidentityprovider.CognitoIdentityProvider, lambdaConn *lambda.Lambda, d *schema.ResourceData) error {
+	if !d.HasChange("event") {
+		return nil
+	}
+	target := cognitoTriggerTarget(d)
+	o, n := d.GetChange("event")
+	old := expandCognitoTrigger(o.([]interface{})[0].(map[string]interface{}), target)
+	trigger := expandCognitoTrigger(n.([]interface{})[0].(map[string]interface{}), target)
+
+	if old.UserPoolId != trigger.UserPoolId || old.TriggerType != trigger.TriggerType {
+		if old.TriggerType != "" {
+			if err := putCognitoTrigger(conn, old, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	if old.UserPoolId != trigger.UserPoolId {
+		_, err := lambdaConn.RemovePermission(&lambda.RemovePermissionInput{
+			FunctionName: aws.String(target),
+			StatementId:  aws.String(cognitoStatementId(old.UserPoolId)),
+		})
+		if err != nil && !isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") {
+			return fmt.Errorf("Error removing permission of user pool %s: %s", old.UserPoolId, err)
+		}
+
+		sourceArn, err := userPoolArn(target, trigger.UserPoolId)
+		if err != nil {
+			return err
+		}
+		account, err := functionArnAccount(target)
+		if err != nil {
+			return err
+		}
+
+		permission := functionPermission{
+			StatementId:   cognitoStatementId(trigger.UserPoolId),
+			FunctionName:  target,
+			SourceArn:     sourceArn,
+			SourceAccount: account,
+			Principal:     "cognito-idp.amazonaws.com",
+			Action:        "lambda:InvokeFunction",
+		}
+		if err := permission.Create(); err != nil && !isAWSErr(err, lambda.ErrCodeResourceConflictException, "") {
+			return fmt.Errorf("Error adding permission of user pool %s: %s", trigger.UserPoolId, err)
+		}
+	}
+
+	return putCognitoTrigger(conn, trigger, true)
+}
+
+// readCognitoTrigger refreshes the event block from the user pool. A
+// trigger no longer invoking the function clears trigger_type so that the
+// next apply sets it again.
+func readCognitoTrigger(conn *cognitoidentityprovider.CognitoIdentityProvider, d *schema.ResourceData) error {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	trigger := expandCognitoTrigger(event, cognitoTriggerTarget(d))
+
+	out, err := conn.DescribeUserPool(&cognitoidentityprovider.DescribeUserPoolInput{
+		UserPoolId: aws.String(trigger.UserPoolId),
+	})
+	if err != nil && !isAWSErr(err, cognitoidentityprovider.ErrCodeResourceNotFoundException, "") {
+		return fmt.Errorf("Error reading user pool %s: %s", trigger.UserPoolId, err)
+	}
+
+	current := ""
+	if field, ok := cognitoLambdaConfigFields[trigger.TriggerType]; ok && out != nil && out.UserPool.LambdaConfig != nil {
+		current = aws.StringValue(*field(out.UserPool.LambdaConfig))
+	}
+
+	if current == "" || unqualifiedFunctionArn(current) != unqualifiedFunctionArn(trigger.FunctionArn) {
+		log.Printf("[WARN] Trigger %s of user pool %s does not invoke %s", trigger.TriggerType, trigger.UserPoolId, trigger.FunctionArn)
+		event["trigger_type"] = ""
+	}
+	sourceArn, err := userPoolArn(d.Get("arn").(string), trigger.UserPoolId)
+	if err != nil {
+		return err
+	}
+	event["user_pool_arn"] = sourceArn
+	event["statement_id"] = cognitoStatementId(trigger.UserPoolId)
+
+	if err := d.Set("event", []interface{}{event}); err != nil {
+		return fmt.Errorf("Error setting event: %s", err)
+	}
+	return nil
+}
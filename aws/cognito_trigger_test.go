@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+func TestUserPoolArn(t *testing.T) {
+	testCases := []struct {
+		FunctionArn string
+		Expected    string
+		Error       bool
+	}{
+		{
+			FunctionArn: "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction:live",
+			Expected:    "arn:aws:cognito-idp:eu-west-1:123456789012:userpool/eu-west-1_AbCdEfGhI",
+		},
+		{FunctionArn: "", Error: true},
+		{FunctionArn: "TestFunction", Error: true},
+		{FunctionArn: "arn:aws:sqs:eu-west-1:123456789012:queue", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		got, err := userPoolArn(testCase.FunctionArn, "eu-west-1_AbCdEfGhI")
+		if testCase.Error != (err != nil) {
+			t.Errorf("%q: expected error %t, got %v", testCase.FunctionArn, testCase.Error, err)
+		}
+		if got != testCase.Expected {
+			t.Errorf("%q: expected %s, got %s", testCase.FunctionArn, testCase.Expected, got)
+		}
+	}
+}
+
+func TestCognitoStatementId(t *testing.T) {
+	if got := cognitoStatementId("eu-west-1_AbCdEfGhI"); got != "CognitoUserPool_eu-west-1_AbCdEfGhI" {
+		t.Errorf("bad statement id: %s", got)
+	}
+}
+
+func TestCognitoLambdaConfigFields(t *testing.T) {
+	for _, triggerType := range cognitoTriggerTypes() {
+		config := &cognitoidentityprovider.LambdaConfigType{}
+		*cognitoLambdaConfigFields[triggerType](config) = aws.String(triggerType)
+
+		expected := &cognitoidentityprovider.LambdaConfigType{}
+		switch triggerType {
+		case "PreSignUp":
+			expected.PreSignUp = aws.String(triggerType)
+		case "CustomMessage":
+			expected.CustomMessage = aws.String(triggerType)
+		case "PostConfirmation":
+			expected.PostConfirmation = aws.String(triggerType)
+		case "PreAuthentication":
+			expected.PreAuthentication = aws.String(triggerType)
+		case "PostAuthentication":
+			expected.PostAuthentication = aws.String(triggerType)
+		case "DefineAuthChallenge":
+			expected.DefineAuthChallenge = aws.String(triggerType)
+		case "CreateAuthChallenge":
+			expected.CreateAuthChallenge = aws.String(triggerType)
+		case "VerifyAuthChallengeResponse":
+			expected.VerifyAuthChallengeResponse = aws.String(triggerType)
+		case "PreTokenGeneration":
+			expected.PreTokenGeneration = aws.String(triggerType)
+		case "UserMigration":
+			expected.UserMigration = aws.String(triggerType)
+		default:
+			t.Errorf("unexpected trigger type %s", triggerType)
+		}
+		if config.String() != expected.String() {
+			t.Errorf("%s: expected %s, got %s", triggerType, expected, config)
+		}
+	}
+}
+
+func TestUpdateUserPoolInput(t *testing.T) {
+	testCases := []struct {
+		Name              string
+		TemporaryPassword *int64
+		ExpectedUnused    *int64
+	}{
+		{Name: "legacy validity", TemporaryPassword: nil, ExpectedUnused: aws.Int64(7)},
+		{Name: "password policy", TemporaryPassword: aws.Int64(3), ExpectedUnused: nil},
+	}
+
+	for _, testCase := range testCases {
+		pool := &cognitoidentityprovider.UserPoolType{
+			Id:                     aws.String("eu-west-1_AbCdEfGhI"),
+			AutoVerifiedAttributes: aws.StringSlice([]string{"email"}),
+			MfaConfiguration:       aws.String("OPTIONAL"),
+			AdminCreateUserConfig: &cognitoidentityprovider.AdminCreateUserConfigType{
+				AllowAdminCreateUserOnly:  aws.Bool(true),
+				UnusedAccountValidityDays: aws.Int64(7),
+			},
+			Policies: &cognitoidentityprovider.UserPoolPolicyType{
+				PasswordPolicy: &cognitoidentityprovider.PasswordPolicyType{
+					MinimumLength:                 aws.Int64(12),
+					TemporaryPasswordValidityDays: testCase.TemporaryPassword,
+				},
+			},
+		}
+
+		input := updateUserPoolInput(pool)
+		if aws.StringValue(input.UserPoolId) != "eu-west-1_AbCdEfGhI" || aws.StringValue(input.MfaConfiguration) != "OPTIONAL" {
+			t.Errorf("%s: settings not kept: %s", testCase.Name, input)
+		}
+		if input.LambdaConfig == nil {
+			t.Errorf("%s: expected an empty LambdaConfig", testCase.Name)
+		}
+		if !aws.BoolValue(input.AdminCreateUserConfig.AllowAdminCreateUserOnly) {
+			t.Errorf("%s: AllowAdminCreateUserOnly not kept", testCase.Name)
+		}
+		if got := input.AdminCreateUserConfig.UnusedAccountValidityDays; aws.Int64Value(got) != aws.Int64Value(testCase.ExpectedUnused) || (got == nil) != (testCase.ExpectedUnused == nil) {
+			t.Errorf("%s: expected UnusedAccountValidityDays %v, got %v", testCase.Name, testCase.ExpectedUnused, got)
+		}
+		if aws.Int64Value(pool.AdminCreateUserConfig.UnusedAccountValidityDays) != 7 {
+			t.Errorf("%s: the described pool was modified", testCase.Name)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// provisionedConcurrencyTimeout is how long to wait for the provisioned
+// concurrency to become READY
+const provisionedConcurrencyTimeout = 15 * time.Minute
+
+// provisionedConcurrencySchema is the provisioned concurrency of a version or alias
+func provisionedConcurrencySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"qualifier": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"amount": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+// putFunctionConcurrency reserves the configured concurrency, -1 removes the reservation
+func putFunctionConcurrency(conn *lambda.Lambda, functionName string, reserved int) error {
+	if reserved < 0 {
+		log.Printf("[DEBUG] Removing reserved concurrency of Lambda function %s", functionName)
+		_, err := conn.DeleteFunctionConcurrency(&lambda.DeleteFunctionConcurrencyInput{
+			FunctionName: aws.String(functionName),
+		})
+		if err != nil && !isAWSErr(err, "ResourceNotFoundException", "") {
+			return fmt.Errorf("Error removing reserved concurrency of Lambda function %s: %s", functionName, err)
+		}
+		return nil
+	}
+
+	log.Printf("[DEBUG] Reserving %d concurrent executions for Lambda function %s", reserved, functionName)
+	_, err := conn.PutFunctionConcurrency(&lambda.PutFunctionConcurrencyInput{
+		FunctionName:                 aws.String(functionName),
+		ReservedConcurrentExecutions: aws.Int64(int64(reserved)),
+	})
+	if err != nil {
+		return fmt.Errorf("Error reserving concurrency of Lambda function %s: %s", functionName, err)
+	}
+	return nil
+}
+
+// putProvisionedConcurrency configures the provisioned concurrency of the
+// qualifier and waits for it to become READY
+func putProvisionedConcurrency(conn *lambda.Lambda, functionName string, config map[string]interface{}) error {
+	qualifier := config["qualifier"].(string)
+
+	log.Printf("[DEBUG] Provisioning %d concurrent executions for Lambda function %s:%s", config["amount"].(int), functionName, qualifier)
+	_, err := conn.PutProvisionedConcurrencyConfig(&lambda.PutProvisionedConcurrencyConfigInput{
+		FunctionName:                    aws.String(functionName),
+		Qualifier:                       aws.String(qualifier),
+		ProvisionedConcurrentExecutions: aws.Int64(int64(config["amount"].(int))),
+	})
+	if err != nil {
+		return fmt.Errorf("Error provisioning concurrency of Lambda function %s:%s: %s", functionName, qualifier, err)
+	}
+
+	return waitForProvisionedConcurrency(conn, functionName, qualifier)
+}
+
+// waitForProvisionedConcurrency waits for the provisioned concurrency to be READY
+func waitForProvisionedConcurrency(conn *lambda.Lambda, functionName, qualifier string) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{lambda.ProvisionedConcurrencyStatusEnumInProgress},
+		Target:  []string{lambda.ProvisionedConcurrencyStatusEnumReady},
+		Refresh: func() (interface{}, string, error) {
+			out, err := conn.GetProvisionedConcurrencyConfig(&lambda.GetProvisionedConcurrencyConfigInput{
+				FunctionName: aws.String(functionName),
+				Qualifier:    aws.String(qualifier),
+			})
+			if err != nil {
+				return nil, "", err
+			}
+
+			status := aws.StringValue(out.Status)
+			if status == lambda.ProvisionedConcurrencyStatusEnumFailed {
+				return out, status, fmt.Errorf("provisioned concurrency failed: %s", aws.StringValue(out.StatusReason))
+			}
+			return out, status, nil
+		},
+		Timeout:    provisionedConcurrencyTimeout,
+		Delay:      5 * time.Second,
+		MinTimeout: 5 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for provisioned concurrency of Lambda function %s:%s: %s", functionName, qualifier, err)
+	}
+	return nil
+}
+
+// deleteProvisionedConcurrency removes the provisioned concurrency of the qualifier
+func deleteProvisionedConcurrency(conn *lambda.Lambda, functionName, qualifier string) error {
+	log.Printf("[DEBUG] Removing provisioned concurrency of Lambda function %s:%s", functionName, qualifier)
+	_, err := conn.DeleteProvisionedConcurrencyConfig(&lambda.DeleteProvisionedConcurrencyConfigInput{
+		FunctionName: aws.String(functionName),
+		Qualifier:    aws.String(qualifier),
+	})
+	if err != nil && !isProvisionedConcurrencyNotFound(err) {
+		return fmt.Errorf("Error removing provisioned concurrency of Lambda function %s:%s: %s", functionName, qualifier, err)
+	}
+	return nil
+}
+
+// createFunctionConcurrency applies the reserved and provisioned concurrency
+// of a new function, the alias must already exist
+func createFunctionConcurrency(conn *lambda.Lambda, d *schema.ResourceData) error {
+	functionName := d.Get("function_name").(string)
+
+	if reserved := d.Get("reserved_concurrent_executions").(int); reserved >= 0 {
+		if err := putFunctionConcurrency(conn, functionName, reserved); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := d.GetOk("provisioned_concurrency"); ok {
+		return putProvisionedConcurrency(conn, functionName, v.([]interface{})[0].(map[string]interface{}))
+	}
+	return nil
+}
+
+// updateFunctionConcurrency applies the changes to the reserved and
+// provisioned concurrency
+func updateFunctionConcurrency(conn *lambda.Lambda, d *schema.ResourceData) error {
+	if d.HasChange("reserved_concurrent_executions") {
+		if err := putFunctionConcurrency(conn, d.Id(), d.Get("reserved_concurrent_executions").(int)); err != nil {
+			return err
+		}
+	}
+
+	if !d.HasChange("provisioned_concurrency") {
+		return nil
+	}
+
+	o, n := d.GetChange("provisioned_concurrency")
+	old, new := o.([]interface{}), n.([]interface{})
+
+	if qualifier := replacedProvisionedConcurrencyQualifier(old, new); qualifier != "" {
+		if err := deleteProvisionedConcurrency(conn, d.Id(), qualifier); err != nil {
+			return err
+		}
+	}
+
+	if len(new) > 0 && new[0] != nil {
+		return putProvisionedConcurrency(conn, d.Id(), new[0].(map[string]interface{}))
+	}
+	return nil
+}
+
+// replacedProvisionedConcurrencyQualifier returns the qualifier whose
+// provisioned concurrency is removed or moved to another qualifier, if any
+func replacedProvisionedConcurrencyQualifier(old, new []interface{}) string {
+	if len(old) == 0 || old[0] == nil {
+		return ""
+	}
+	oldQualifier := old[0].(map[string]interface{})["qualifier"].(string)
+	if len(new) == 0 || new[0] == nil || new[0].(map[string]interface{})["qualifier"].(string) != oldQualifier {
+		return oldQualifier
+	}
+	return ""
+}
+
+// isProvisionedConcurrencyNotFound reports whether the provisioned
+// concurrency, or the version or alias it was configured on, is gone
+func isProvisionedConcurrencyNotFound(err error) bool {
+	return isAWSErr(err, "ProvisionedConcurrencyConfigNotFoundException", "") || isAWSErr(err, "ResourceNotFoundException", "")
+}
+
+// flattenReservedConcurrency returns the reserved concurrency, -1 when the
+// function has none
+func flattenReservedConcurrency(out *lambda.GetFunctionConcurrencyOutput) int {
+	if out == nil || out.ReservedConcurrentExecutions == nil {
+		return -1
+	}
+	return int(aws.Int64Value(out.ReservedConcurrentExecutions))
+}
+
+// readFunctionConcurrency refreshes the reserved and provisioned concurrency
+func readFunctionConcurrency(conn *lambda.Lambda, d *schema.ResourceData) error {
+	out, err := conn.GetFunctionConcurrency(&lambda.GetFunctionConcurrencyInput{
+		FunctionName: aws.String(d.Id()),
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading concurrency of Lambda function %s: %s", d.Id(), err)
+	}
+	d.Set("reserved_concurrent_executions", flattenReservedConcurrency(out))
+
+	v, ok := d.GetOk("provisioned_concurrency")
+	if !ok {
+		return nil
+	}
+	qualifier := v.([]interface{})[0].(map[string]interface{})["qualifier"].(string)
+
+	config, err := conn.GetProvisionedConcurrencyConfig(&lambda.GetProvisionedConcurrencyConfigInput{
+		FunctionName: aws.String(d.Id()),
+		Qualifier:    aws.String(qualifier),
+	})
+	if err != nil {
+		if isProvisionedConcurrencyNotFound(err) {
+			log.Printf("[WARN] Provisioned concurrency of %s:%s not found, removing from state", d.Id(), qualifier)
+			return d.Set("provisioned_concurrency", nil)
+		}
+		return fmt.Errorf("Error reading provisioned concurrency of Lambda function %s:%s: %s", d.Id(), qualifier, err)
+	}
+
+	return d.Set("provisioned_concurrency", []interface{}{
+		map[string]interface{}{
+			"qualifier": qualifier,
+			"amount":    int(aws.Int64Value(config.RequestedProvisionedConcurrentExecutions)),
+		},
+	})
+}
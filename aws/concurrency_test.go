@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+func TestReplacedProvisionedConcurrencyQualifier(t *testing.T) {
+	live := []interface{}{map[string]interface{}{"qualifier": "live", "amount": 2}}
+	liveMore := []interface{}{map[string]interface{}{"qualifier": "live", "amount": 5}}
+	canary := []interface{}{map[string]interface{}{"qualifier": "canary", "amount": 2}}
+
+	testCases := []struct {
+		Name     string
+		Old      []interface{}
+		New      []interface{}
+		Expected string
+	}{
+		{Name: "added", Old: nil, New: live, Expected: ""},
+		{Name: "amount changed", Old: live, New: liveMore, Expected: ""},
+		{Name: "qualifier changed", Old: live, New: canary, Expected: "live"},
+		{Name: "removed", Old: live, New: nil, Expected: "live"},
+		{Name: "empty block", Old: live, New: []interface{}{nil}, Expected: "live"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := replacedProvisionedConcurrencyQualifier(testCase.Old, testCase.New); got != testCase.Expected {
+				t.Errorf("expected %q, got %q", testCase.Expected, got)
+			}
+		})
+	}
+}
+
+func TestIsProvisionedConcurrencyNotFound(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Err      error
+		Expected bool
+	}{
+		{Name: "config not found", Err: awserr.New("ProvisionedConcurrencyConfigNotFoundException", "No Provisioned Concurrency Config found", nil), Expected: true},
+		{Name: "alias not found", Err: awserr.New("ResourceNotFoundException", "Function not found", nil), Expected: true},
+		{Name: "throttled", Err: awserr.New("TooManyRequestsException", "Rate exceeded", nil), Expected: false},
+		{Name: "other error", Err: errors.New("ResourceNotFoundException"), Expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := isProvisionedConcurrencyNotFound(testCase.Err); got != testCase.Expected {
+				t.Errorf("expected %t, got %t", testCase.Expected, got)
+			}
+		})
+	}
+}
+
+func TestFlattenReservedConcurrency(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Output   *lambda.GetFunctionConcurrencyOutput
+		Expected int
+	}{
+		{Name: "no output", Output: nil, Expected: -1},
+		{Name: "unreserved", Output: &lambda.GetFunctionConcurrencyOutput{}, Expected: -1},
+		{Name: "reserved", Output: &lambda.GetFunctionConcurrencyOutput{ReservedConcurrentExecutions: aws.Int64(10)}, Expected: 10},
+		{Name: "throttled", Output: &lambda.GetFunctionConcurrencyOutput{ReservedConcurrentExecutions: aws.Int64(0)}, Expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := flattenReservedConcurrency(testCase.Output); got != testCase.Expected {
+				t.Errorf("expected %d, got %d", testCase.Expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// Config is the provider configuration shared by every resource
+type Config struct {
+	// ArtifactBucket is the bucket where deployment packages too large to be
+	// uploaded directly are staged
+	ArtifactBucket string
+	ArtifactPrefix string
+
+	// AllowUnknownRuntimes accepts runtimes missing from the runtime catalog
+	AllowUnknownRuntimes bool
+
+	// uploads bounds the number of deployment packages held in memory
+	uploads chan struct{}
+}
+
+// ProviderConfigure builds the Config from the provider block
+func ProviderConfigure(d *schema.ResourceData) (interface{}, error) {
+	return &Config{
+		ArtifactBucket:       d.Get("artifact_bucket").(string),
+		ArtifactPrefix:       d.Get("artifact_prefix").(string),
+		AllowUnknownRuntimes: d.Get("allow_unknown_runtimes").(bool),
+		uploads:              make(chan struct{}, d.Get("max_concurrent_uploads").(int)),
+	}, nil
+}
+
+// acquireUpload waits for an upload slot and returns the function releasing
+// it, only the first call of the function releases the slot
+func (c *Config) acquireUpload() func() {
+	if c == nil || c.uploads == nil {
+		return func() {}
+	}
+	c.uploads <- struct{}{}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-c.uploads
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func DataSourceFunction() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFunctionRead,
+
+		Schema: map[string]*schema.Schema{
+			"function_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"qualifier": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"arn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"qualified_arn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"invoke_arn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"handler": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"runtime": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"role": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"memory_size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"timeout": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"source_code_hash": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"source_code_size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"environment": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"variables": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"vpc_config": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subnet_ids": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+						"security_group_ids": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"layers": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"s3_triggers": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"bucket": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"statement_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"notification_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"function_arn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"event_types": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+						"object_prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"object_suffix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"http_triggers": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"api_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resource_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"http_method": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"function_arn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"statement_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"event_source_mappings": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"event_source_arn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"function_arn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"batch_size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceFunctionRead(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+
+	functionName := d.Get("function_name").(string)
+	qualifier := d.Get("qualifier").(string)
+	log.Printf("[DEBUG] Reading Serverless AWS Function data source %s (qualifier %q)", functionName, qualifier)
+
+	input := &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+
+	output, err := conn.GetFunction(input)
+	if err != nil {
+		return fmt.Errorf("Error reading Lambda function %s: %s", functionName, err)
+	}
+	config := output.Configuration
+
+	qualifiedArn := aws.StringValue(config.FunctionArn)
+	arn := unqualifiedFunctionArn(qualifiedArn)
+	if qualifier == "" {
+		qualifiedArn = arn + ":" + aws.StringValue(config.Version)
+	}
+
+	d.SetId(aws.StringValue(config.FunctionName))
+	d.Set("arn", arn)
+	d.Set("qualified_arn", qualifiedArn)
+	d.Set("invoke_arn", lambdaFunctionInvokeArn(auth.Region, arn))
+	d.Set("version", config.Version)
+	d.Set("description", config.Description)
+	d.Set("handler", config.Handler)
+	d.Set("runtime", config.Runtime)
+	d.Set("role", config.Role)
+	d.Set("memory_size", config.MemorySize)
+	d.Set("timeout", config.Timeout)
+	d.Set("last_modified", config.LastModified)
+	d.Set("source_code_hash", config.CodeSha256)
+	d.Set("source_code_size", config.CodeSize)
+
+	environment := []interface{}{}
+	if config.Environment != nil && len(config.Environment.Variables) > 0 {
+		environment = append(environment, map[string]interface{}{
+			"variables": aws.StringValueMap(config.Environment.Variables),
+		})
+	}
+	if err := d.Set("environment", environment); err != nil {
+		return fmt.Errorf("Error setting environment: %s", err)
+	}
+
+	vpcConfig := []interface{}{}
+	if config.VpcConfig != nil && aws.StringValue(config.VpcConfig.VpcId) != "" {
+		vpcConfig = append(vpcConfig, map[string]interface{}{
+			"subnet_ids":         aws.StringValueSlice(config.VpcConfig.SubnetIds),
+			"security_group_ids": aws.StringValueSlice(config.VpcConfig.SecurityGroupIds),
+			"vpc_id":             aws.StringValue(config.VpcConfig.VpcId),
+		})
+	}
+	if err := d.Set("vpc_config", vpcConfig); err != nil {
+		return fmt.Errorf("Error setting vpc_config: %s", err)
+	}
+
+	layers := make([]string, 0, len(config.Layers))
+	for _, layer := range config.Layers {
+		layers = append(layers, aws.StringValue(layer.Arn))
+	}
+	d.Set("layers", layers)
+
+	statements, err := readFunctionPolicyStatements(conn, functionName, qualifier)
+	if err != nil {
+		return err
+	}
+
+	s3Triggers, err := readFunctionS3Triggers(auth.Client.S3Conn, arn, statements)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("s3_triggers", s3Triggers); err != nil {
+		return fmt.Errorf("Error setting s3_triggers: %s", err)
+	}
+
+	httpTriggers, err := readFunctionHTTPTriggers(auth.Client.ApigatewayConn, arn, statements)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("http_triggers", httpTriggers); err != nil {
+		return fmt.Errorf("Error setting http_triggers: %s", err)
+	}
+
+	mappings, err := readFunctionEventSourceMappings(conn, functionName, qualifier)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("event_source_mappings", mappings); err != nil {
+		return fmt.Errorf("Error setting event_source_mappings: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func DataSourceHTTPApi() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceHTTPApiRead,
+
+		Schema: map[string]*schema.Schema{
+			"api_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"api_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"root_resource_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"stages": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"stage_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"deployment_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"invoke_url": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"invoke_urls": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"bindings": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"resource_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"http_method": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"function_arn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceHTTPApiRead(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.ApigatewayConn
+
+	apiName := d.Get("api_name").(string)
+	log.Printf("[DEBUG] Reading Serverless AWS HTTP Api data source %s", apiName)
+
+	restAPI, err := findRestApiByName(conn, apiName)
+	if err != nil {
+		return err
+	}
+	apiID := aws.StringValue(restAPI.Id)
+
+	d.SetId(apiID)
+	d.Set("api_id", apiID)
+	d.Set("description", restAPI.Description)
+	if restAPI.CreatedDate != nil {
+		d.Set("created_date", restAPI.CreatedDate.String())
+	}
+
+	rootResourceID, err := readRestApiRootResourceId(conn, apiID)
+	if err != nil {
+		return err
+	}
+	d.Set("root_resource_id", rootResourceID)
+
+	stagesOutput, err := conn.GetStages(&apigateway.GetStagesInput{
+		RestApiId: aws.String(apiID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading stages of rest api %s: %s", apiID, err)
+	}
+
+	stages := make([]interface{}, 0, len(stagesOutput.Item))
+	invokeUrls := make(map[string]interface{})
+	for _, stage := range stagesOutput.Item {
+		stageName := aws.StringValue(stage.StageName)
+		invokeURL := restApiInvokeUrl(apiID, auth.Region, stageName)
+		stages = append(stages, map[string]interface{}{
+			"stage_name":    stageName,
+			"deployment_id": aws.StringValue(stage.DeploymentId),
+			"invoke_url":    invokeURL,
+		})
+		invokeUrls[stageName] = invokeURL
+	}
+	if err := d.Set("stages", stages); err != nil {
+		return fmt.Errorf("Error setting stages: %s", err)
+	}
+	if err := d.Set("invoke_urls", invokeUrls); err != nil {
+		return fmt.Errorf("Error setting invoke_urls: %s", err)
+	}
+
+	bindings, err := readRestApiFunctionBindings(conn, apiID)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("bindings", flattenRestApiBindings(bindings)); err != nil {
+		return fmt.Errorf("Error setting bindings: %s", err)
+	}
+
+	return nil
+}
+
+// findRestApiByName returns the only rest api with the given name
+func findRestApiByName(conn *apigateway.APIGateway, name string) (*apigateway.RestApi, error) {
+	var items []*apigateway.RestApi
+	err := conn.GetRestApisPages(&apigateway.GetRestApisInput{}, func(page *apigateway.GetRestApisOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing rest apis: %s", err)
+	}
+	return selectRestApiByName(items, name)
+}
+
+// selectRestApiByName returns the only rest api of items with the given name
+func selectRestApiByName(items []*apigateway.RestApi, name string) (*apigateway.RestApi, error) {
+	var matches []*apigateway.RestApi
+	for _, item := range items {
+		if aws.StringValue(item.Name) == name {
+			matches = append(matches, item)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("No rest api found with name %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("Multiple rest apis (%d) found with name %q", len(matches), name)
+	}
+}
+
+// readRestApiRootResourceId returns the id of the "/" resource of the rest api
+func readRestApiRootResourceId(conn *apigateway.APIGateway, apiID string) (string, error) {
+	var rootResourceID string
+	err := conn.GetResourcesPages(&apigateway.GetResourcesInput{
+		RestApiId: aws.String(apiID),
+	}, func(page *apigateway.GetResourcesOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			if aws.StringValue(item.Path) == "/" {
+				rootResourceID = aws.StringValue(item.Id)
+				return false
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error reading resources of rest api %s: %s", apiID, err)
+	}
+	return rootResourceID, nil
+}
+
+// restApiInvokeUrl returns the URL used to invoke a rest api stage
+func restApiInvokeUrl(apiID, region, stageName string) string {
+	return fmt.Sprintf("https://%s.execute-api.%s.%s/%s", apiID, region, partitionDNSSuffix(region), stageName)
+}
+
+// flattenRestApiBindings returns the bindings attribute, the api id is the
+// one of the data source
+func flattenRestApiBindings(bindings []map[string]interface{}) []interface{} {
+	flattened := make([]interface{}, 0, len(bindings))
+	for _, binding := range bindings {
+		delete(binding, "api_id")
+		flattened = append(flattened, binding)
+	}
+	return flattened
+}
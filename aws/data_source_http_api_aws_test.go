@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+)
+
+func TestSelectRestApiByName(t *testing.T) {
+	items := []*apigateway.RestApi{
+		{Id: aws.String("abc123"), Name: aws.String("orders")},
+		{Id: aws.String("def456"), Name: aws.String("users")},
+		{Id: aws.String("ghi789"), Name: aws.String("users")},
+	}
+
+	testCases := []struct {
+		Name     string
+		Expected string
+		Error    bool
+	}{
+		{Name: "orders", Expected: "abc123"},
+		{Name: "users", Error: true},
+		{Name: "missing", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		restAPI, err := selectRestApiByName(items, testCase.Name)
+		if testCase.Error != (err != nil) {
+			t.Errorf("%s: expected error %t, got %v", testCase.Name, testCase.Error, err)
+			continue
+		}
+		if err == nil && aws.StringValue(restAPI.Id) != testCase.Expected {
+			t.Errorf("%s: expected %s, got %s", testCase.Name, testCase.Expected, aws.StringValue(restAPI.Id))
+		}
+	}
+}
+
+func TestRestApiInvokeUrl(t *testing.T) {
+	testCases := []struct {
+		Region   string
+		Stage    string
+		Expected string
+	}{
+		{Region: "eu-west-1", Stage: "prod", Expected: "https://abc123.execute-api.eu-west-1.amazonaws.com/prod"},
+		{Region: "us-east-1", Stage: "dev", Expected: "https://abc123.execute-api.us-east-1.amazonaws.com/dev"},
+		{Region: "cn-north-1", Stage: "prod", Expected: "https://abc123.execute-api.cn-north-1.amazonaws.com.cn/prod"},
+	}
+
+	for _, testCase := range testCases {
+		if got := restApiInvokeUrl("abc123", testCase.Region, testCase.Stage); got != testCase.Expected {
+			t.Errorf("%s/%s: expected %s, got %s", testCase.Region, testCase.Stage, testCase.Expected, got)
+		}
+	}
+}
+
+func TestFlattenRestApiBindings(t *testing.T) {
+	bindings := []map[string]interface{}{
+		{
+			"api_id":       "abc123",
+			"resource_id":  "res1",
+			"path":         "/orders",
+			"http_method":  "GET",
+			"function_arn": "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction",
+		},
+	}
+
+	flattened := flattenRestApiBindings(bindings)
+	if len(flattened) != 1 {
+		t.Fatalf("expected 1 binding, got %d", len(flattened))
+	}
+	binding := flattened[0].(map[string]interface{})
+	if _, ok := binding["api_id"]; ok {
+		t.Errorf("expected api_id to be removed: %v", binding)
+	}
+	for _, k := range []string{"resource_id", "path", "http_method", "function_arn"} {
+		if binding[k] != bindings[0][k] {
+			t.Errorf("bad %s: %v", k, binding[k])
+		}
+	}
+
+	if flattened := flattenRestApiBindings(nil); len(flattened) != 0 {
+		t.Errorf("expected no bindings, got %v", flattened)
+	}
+}
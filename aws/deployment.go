@@ -0,0 +1,223 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+var validDeploymentPreferenceTypes = []string{
+	"Canary10Percent5Minutes",
+	"Canary10Percent10Minutes",
+	"Canary10Percent15Minutes",
+	"Canary10Percent30Minutes",
+	"Linear10PercentEvery1Minute",
+	"Linear10PercentEvery2Minutes",
+	"Linear10PercentEvery3Minutes",
+	"Linear10PercentEvery10Minutes",
+	"AllAtOnce",
+}
+
+var (
+	canaryDeploymentRegexp = regexp.MustCompile(`^Canary(\d+)Percent(\d+)Minutes?$`)
+	linearDeploymentRegexp = regexp.MustCompile(`^Linear(\d+)PercentEvery(\d+)Minutes?$`)
+)
+
+// deploymentAlarmPollInterval is how often the alarms are checked while the
+// traffic is shifting
+const deploymentAlarmPollInterval = 30 * time.Second
+
+// deploymentPreferenceSchema is the gradual traffic shifting of the alias
+func deploymentPreferenceSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice(validDeploymentPreferenceTypes, false),
+				},
+				"alarms": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+// customizeDiffDeploymentPreference checks that the traffic shifted by the
+// deployment preference has an alias to go through
+func customizeDiffDeploymentPreference(d *schema.ResourceDiff, m interface{}) error {
+	if _, ok := d.GetOk("deployment_preference"); !ok {
+		return nil
+	}
+	if _, ok := d.GetOk("alias"); !ok {
+		return fmt.Errorf("deployment_preference requires an alias block")
+	}
+	if !d.Get("publish").(bool) {
+		return fmt.Errorf("deployment_preference requires publish to be true")
+	}
+	return nil
+}
+
+// deploymentStep routes Weight of the traffic to the new version for Wait
+type deploymentStep struct {
+	Weight float64
+	Wait   time.Duration
+}
+
+// deploymentSteps returns the traffic shifting steps of a deployment type,
+// the final switch of the alias to the new version is not included
+func deploymentSteps(deploymentType string) ([]deploymentStep, error) {
+	if deploymentType == "AllAtOnce" {
+		return nil, nil
+	}
+
+	if match := canaryDeploymentRegexp.FindStringSubmatch(deploymentType); match != nil {
+		percent, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		return []deploymentStep{
+			{Weight: float64(percent) / 100, Wait: time.Duration(minutes) * time.Minute},
+		}, nil
+	}
+
+	if match := linearDeploymentRegexp.FindStringSubmatch(deploymentType); match != nil {
+		percent, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		var steps []deploymentStep
+		for weight := percent; weight < 100; weight += percent {
+			steps = append(steps, deploymentStep{
+				Weight: float64(weight) / 100,
+				Wait:   time.Duration(minutes) * time.Minute,
+			})
+		}
+		return steps, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported deployment preference type %q", deploymentType)
+}
+
+// shiftFunctionAlias gradually moves the alias from oldVersion to newVersion
+// following the deployment preference. If one of the alarms fires, or the
+// update timeout expires, the alias is rolled back to oldVersion and an error
+// is returned.
+func shiftFunctionAlias(conn *lambda.Lambda, d *schema.ResourceData, input *lambda.UpdateAliasInput, oldVersion string) error {
+	preference := d.Get("deployment_preference").([]interface{})[0].(map[string]interface{})
+	steps, err := deploymentSteps(preference["type"].(string))
+	if err != nil {
+		return err
+	}
+	alarms := expandStringValueList(preference["alarms"].([]interface{}))
+	newVersion := aws.StringValue(input.FunctionVersion)
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutUpdate))
+
+	cw := cloudwatch.New(auth.Sess)
+
+	for _, step := range steps {
+		log.Printf("[INFO] Routing %.0f%% of alias %s traffic to version %s", step.Weight*100, *input.Name, newVersion)
+		_, err := conn.UpdateAlias(&lambda.UpdateAliasInput{
+			FunctionName:    input.FunctionName,
+			Name:            input.Name,
+			Description:     input.Description,
+			FunctionVersion: aws.String(oldVersion),
+			RoutingConfig: &lambda.AliasRoutingConfiguration{
+				AdditionalVersionWeights: map[string]*float64{
+					newVersion: aws.Float64(step.Weight),
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Error shifting Lambda alias %s traffic: %s", *input.Name, err)
+		}
+
+		if err := waitDeploymentStep(cw, alarms, step.Wait, deadline); err != nil {
+			log.Printf("[WARN] Rolling back alias %s to version %s: %s", *input.Name, oldVersion, err)
+			_, rerr := conn.UpdateAlias(&lambda.UpdateAliasInput{
+				FunctionName:    input.FunctionName,
+				Name:            input.Name,
+				Description:     input.Description,
+				FunctionVersion: aws.String(oldVersion),
+				RoutingConfig: &lambda.AliasRoutingConfiguration{
+					AdditionalVersionWeights: map[string]*float64{},
+				},
+			})
+			if rerr != nil {
+				return fmt.Errorf("Error rolling back Lambda alias %s to version %s: %s (rollback cause: %s)", *input.Name, oldVersion, rerr, err)
+			}
+			return fmt.Errorf("Deployment of version %s rolled back to version %s: %s", newVersion, oldVersion, err)
+		}
+	}
+
+	log.Printf("[INFO] Routing all alias %s traffic to version %s", *input.Name, newVersion)
+	if _, err := conn.UpdateAlias(input); err != nil {
+		return fmt.Errorf("Error updating Lambda alias %s: %s", *input.Name, err)
+	}
+	return nil
+}
+
+// waitDeploymentStep waits for the step duration, returning an error as soon
+// as one of the alarms is in ALARM state or the deadline is reached
+func waitDeploymentStep(cw *cloudwatch.CloudWatch, alarms []string, wait time.Duration, deadline time.Time) error {
+	end := time.Now().Add(wait)
+	for {
+		if err := checkDeploymentAlarms(cw, alarms); err != nil {
+			return err
+		}
+
+		remaining := time.Until(end)
+		if remaining <= 0 {
+			return nil
+		}
+		timeout := time.Until(deadline)
+		if timeout <= 0 {
+			return fmt.Errorf("timeout while shifting the traffic, %s left in the step", remaining.Round(time.Second))
+		}
+		if remaining > timeout {
+			remaining = timeout
+		}
+		if remaining > deploymentAlarmPollInterval {
+			remaining = deploymentAlarmPollInterval
+		}
+		time.Sleep(remaining)
+	}
+}
+
+// checkDeploymentAlarms returns an error listing the alarms in ALARM state
+func checkDeploymentAlarms(cw *cloudwatch.CloudWatch, alarms []string) error {
+	if len(alarms) == 0 {
+		return nil
+	}
+
+	out, err := cw.DescribeAlarms(&cloudwatch.DescribeAlarmsInput{
+		AlarmNames: aws.StringSlice(alarms),
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading deployment alarms: %s", err)
+	}
+
+	var firing []string
+	for _, alarm := range out.MetricAlarms {
+		if aws.StringValue(alarm.StateValue) == cloudwatch.StateValueAlarm {
+			firing = append(firing, aws.StringValue(alarm.AlarmName))
+		}
+	}
+	if len(firing) > 0 {
+		return fmt.Errorf("alarms in ALARM state: %s", strings.Join(firing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeploymentSteps(t *testing.T) {
+	testCases := []struct {
+		Type     string
+		Expected []deploymentStep
+		Error    bool
+	}{
+		{
+			Type: "AllAtOnce",
+		},
+		{
+			Type: "Canary10Percent5Minutes",
+			Expected: []deploymentStep{
+				{Weight: 0.1, Wait: 5 * time.Minute},
+			},
+		},
+		{
+			Type: "Linear10PercentEvery1Minute",
+			Expected: []deploymentStep{
+				{Weight: 0.1, Wait: time.Minute},
+				{Weight: 0.2, Wait: time.Minute},
+				{Weight: 0.3, Wait: time.Minute},
+				{Weight: 0.4, Wait: time.Minute},
+				{Weight: 0.5, Wait: time.Minute},
+				{Weight: 0.6, Wait: time.Minute},
+				{Weight: 0.7, Wait: time.Minute},
+				{Weight: 0.8, Wait: time.Minute},
+				{Weight: 0.9, Wait: time.Minute},
+			},
+		},
+		{
+			Type:  "Blue/Green",
+			Error: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Type, func(t *testing.T) {
+			got, err := deploymentSteps(testCase.Type)
+			if testCase.Error {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+
+	for _, deploymentType := range validDeploymentPreferenceTypes {
+		if _, err := deploymentSteps(deploymentType); err != nil {
+			t.Errorf("%s: unexpected error: %s", deploymentType, err)
+		}
+	}
+}
+
+func TestWaitDeploymentStep(t *testing.T) {
+	if err := waitDeploymentStep(nil, nil, 0, time.Now()); err != nil {
+		t.Errorf("unexpected error at the end of the step: %s", err)
+	}
+	if err := waitDeploymentStep(nil, nil, time.Hour, time.Now().Add(-time.Second)); err == nil {
+		t.Error("expected error past the deadline")
+	}
+
+	start := time.Now()
+	if err := waitDeploymentStep(nil, nil, time.Hour, time.Now().Add(50*time.Millisecond)); err == nil {
+		t.Error("expected error at the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > deploymentAlarmPollInterval {
+		t.Errorf("waited %s, past the deadline", elapsed)
+	}
+}
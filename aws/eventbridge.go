@@ -0,0 +1,383 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	// eventBridgeDefaultBus is the event bus receiving the AWS service events
+	eventBridgeDefaultBus = "default"
+	// eventBridgeMaxPatternLength is the size limit of an event pattern
+	eventBridgeMaxPatternLength = 4096
+)
+
+var invalidStatementIdCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// validateEventPattern checks that the event pattern is a JSON object
+func validateEventPattern(v interface{}, k string) (ws []string, errors []error) {
+	pattern, err := normalizeEventPattern(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a JSON object: %s", k, err))
+		return
+	}
+	if len(pattern) > eventBridgeMaxPatternLength {
+		errors = append(errors, fmt.Errorf("%q must be at most %d characters once normalized, got %d", k, eventBridgeMaxPatternLength, len(pattern)))
+	}
+	return
+}
+
+// normalizeEventPattern returns the compact form of the event pattern with
+// sorted keys, so that formatting changes do not show as diffs. Numbers and
+// HTML characters are kept as written, EventBridge matches them literally.
+func normalizeEventPattern(pattern string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(pattern))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return "", err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return "", fmt.Errorf("unexpected content after the JSON object")
+	}
+
+	var normalized bytes.Buffer
+	encoder := json.NewEncoder(&normalized)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(object); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(normalized.String(), "\n"), nil
+}
+
+// eventPatternStateFunc stores the normalized event pattern, invalid
+// patterns are left to validateEventPattern
+func eventPatternStateFunc(v interface{}) string {
+	pattern, err := normalizeEventPattern(v.(string))
+	if err != nil {
+		return v.(string)
+	}
+	return pattern
+}
+
+// validateSqsQueueArn checks that the dead letter queue is an SQS queue ARN
+func validateSqsQueueArn(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+	parsed, err := arn.Parse(value)
+	if err != nil || parsed.Service != "sqs" {
+		errors = append(errors, fmt.Errorf("%q must be the ARN of an SQS queue, got %q", k, value))
+	}
+	return
+}
+
+// eventBridgeStatementId returns the id of the permission allowing the rule
+// to invoke the function
+func eventBridgeStatementId(busName, ruleName string) string {
+	id := "EventBridge_" + invalidStatementIdCharsRegexp.ReplaceAllString(busName+"_"+ruleName, "_")
+	if len(id) > 100 {
+		id = id[:100]
+	}
+	return id
+}
+
+// eventBridgeRuleArn returns the ARN of a rule in the account and region of
+// the function, rules of custom buses are namespaced by the bus name
+func eventBridgeRuleArn(functionArn, busName, ruleName string) string {
+	parts := strings.Split(functionArn, ":")
+	resource := "rule/" + ruleName
+	if busName != eventBridgeDefaultBus {
+		resource = "rule/" + busName + "/" + ruleName
+	}
+	return fmt.Sprintf("arn:%s:events:%s:%s:%s", parts[1], parts[3], parts[4], resource)
+}
+
+// eventBridgeTrigger is the rule of the event block and its target
+type eventBridgeTrigger struct {
+	RuleName    string
+	BusName     string
+	Pattern     string
+	Description string
+	Target      *eventbridge.Target
+}
+
+// expandEventBridgeTrigger returns the rule and target of the event block
+func expandEventBridgeTrigger(d *schema.ResourceData, functionArn string) *eventBridgeTrigger {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	ruleName := event["rule_name"].(string)
+	if ruleName == "" {
+		ruleName = d.Get("function_name").(string)
+	}
+
+	target := &eventbridge.Target{
+		Id:  aws.String(d.Get("function_name").(string)),
+		Arn: aws.String(functionArn),
+		RetryPolicy: &eventbridge.RetryPolicy{
+			MaximumRetryAttempts:     aws.Int64(int64(event["maximum_retry_attempts"].(int))),
+			MaximumEventAgeInSeconds: aws.Int64(int64(event["maximum_event_age_in_seconds"].(int))),
+		},
+	}
+	if v := event["dead_letter_arn"].(string); v != "" {
+		target.DeadLetterConfig = &eventbridge.DeadLetterConfig{Arn: aws.String(v)}
+	}
+	if v := event["input_transformer"].([]interface{}); len(v) > 0 && v[0] != nil {
+		transformer := v[0].(map[string]interface{})
+		target.InputTransformer = &eventbridge.InputTransformer{
+			InputPathsMap: expandStringMap(transformer["input_paths"].(map[string]interface{})),
+			InputTemplate: aws.String(transformer["input_template"].(string)),
+		}
+	}
+
+	return &eventBridgeTrigger{
+		RuleName:    ruleName,
+		BusName:     event["event_bus_name"].(string),
+		Pattern:     eventPatternStateFunc(event["event_pattern"].(string)),
+		Description: event["description"].(string),
+		Target:      target,
+	}
+}
+
+// putEventBridgeRule creates or updates the rule
+func putEventBridgeRule(conn *eventbridge.EventBridge, t *eventBridgeTrigger) (string, error) {
+	input := &eventbridge.PutRuleInput{
+		Name:         aws.String(t.RuleName),
+		EventBusName: aws.String(t.BusName),
+		EventPattern: aws.String(t.Pattern),
+		State:        aws.String(eventbridge.RuleStateEnabled),
+	}
+	if t.Description != "" {
+		input.Description = aws.String(t.Description)
+	}
+
+	log.Printf("[DEBUG] Putting EventBridge rule: %s", input)
+	out, err := conn.PutRule(input)
+	if err != nil {
+		return "", fmt.Errorf("Error putting EventBridge rule %s: %s", t.RuleName, err)
+	}
+	return aws.StringValue(out.RuleArn), nil
+}
+
+// putEventBridgeTarget creates or updates the function target of the rule
+func putEventBridgeTarget(conn *eventbridge.EventBridge, t *eventBridgeTrigger) error {
+	log.Printf("[DEBUG] Putting target of EventBridge rule %s: %s", t.RuleName, t.Target)
+	out, err := conn.PutTargets(&eventbridge.PutTargetsInput{
+		Rule:         aws.String(t.RuleName),
+		EventBusName: aws.String(t.BusName),
+		Targets:      []*eventbridge.Target{t.Target},
+	})
+	if err != nil {
+		return fmt.Errorf("Error putting target of EventBridge rule %s: %s", t.RuleName, err)
+	}
+	if len(out.FailedEntries) > 0 {
+		entry := out.FailedEntries[0]
+		return fmt.Errorf("Error putting target of EventBridge rule %s: %s: %s", t.RuleName, aws.StringValue(entry.ErrorCode), aws.StringValue(entry.ErrorMessage))
+	}
+	return nil
+}
+
+// deleteEventBridgeTrigger removes the target of the function and the rule
+func deleteEventBridgeTrigger(conn *eventbridge.EventBridge, t *eventBridgeTrigger) error {
+	_, err := conn.RemoveTargets(&eventbridge.RemoveTargetsInput{
+		Rule:         aws.String(t.RuleName),
+		EventBusName: aws.String(t.BusName),
+		Ids:          []*string{t.Target.Id},
+	})
+	if err != nil && !isAWSErr(err, eventbridge.ErrCodeResourceNotFoundException, "") {
+		return fmt.Errorf("Error removing target of EventBridge rule %s: %s", t.RuleName, err)
+	}
+
+	_, err = conn.DeleteRule(&eventbridge.DeleteRuleInput{
+		Name:         aws.String(t.RuleName),
+		EventBusName: aws.String(t.BusName),
+	})
+	if err != nil && !isAWSErr(err, eventbridge.ErrCodeResourceNotFoundException, "") {
+		return fmt.Errorf("Error deleting EventBridge rule %s: %s", t.RuleName, err)
+	}
+	return nil
+}
+
+// eventBridgeRule is the rule of the trigger, created and rolled back along
+// with the function dependencies
+type eventBridgeRule struct {
+	Trigger *eventBridgeTrigger
+}
+
+// Create puts the rule
+func (r *eventBridgeRule) Create() error {
+	_, err := putEventBridgeRule(eventbridge.New(auth.Sess), r.Trigger)
+	return err
+}
+
+// Delete removes the rule with its target
+func (r *eventBridgeRule) Delete() error {
+	return deleteEventBridgeTrigger(eventbridge.New(auth.Sess), r.Trigger)
+}
+
+// eventBridgeTarget is the function target of the rule, created and rolled
+// back along with the function dependencies
+type eventBridgeTarget struct {
+	Trigger *eventBridgeTrigger
+}
+
+// Create puts the target
+func (r *eventBridgeTarget) Create() error {
+	return putEventBridgeTarget(eventbridge.New(auth.Sess), r.Trigger)
+}
+
+// Delete removes the target
+func (r *eventBridgeTarget) Delete() error {
+	_, err := eventbridge.New(auth.Sess).RemoveTargets(&eventbridge.RemoveTargetsInput{
+		Rule:         aws.String(r.Trigger.RuleName),
+		EventBusName: aws.String(r.Trigger.BusName),
+		Ids:          []*string{r.Trigger.Target.Id},
+	})
+	return err
+}
+
+// eventBridgeCreateFunctionInput creates the function invoked by the rule
+// of the event block
+type eventBridgeCreateFunctionInput struct {
+	FunctionInput *lambda.CreateFunctionInput
+	Data          *schema.ResourceData
+}
+
+// CreateDependencies creates the rule, allows it to invoke the function and
+// targets the function, rolling all of them back on error
+func (input eventBridgeCreateFunctionInput) CreateDependencies(lambdaResult *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	functionArn := aws.StringValue(lambdaResult.FunctionArn)
+	trigger := expandEventBridgeTrigger(input.Data, functionArn)
+	ruleArn := eventBridgeRuleArn(functionArn, trigger.BusName, trigger.RuleName)
+
+	permission := functionPermission{
+		StatementId:  eventBridgeStatementId(trigger.BusName, trigger.RuleName),
+		FunctionName: functionArn,
+		SourceArn:    ruleArn,
+		Principal:    "events.amazonaws.com",
+		Action:       "lambda:InvokeFunction",
+	}
+	err := executeCreate(
+		&eventBridgeRule{Trigger: trigger},
+		&permission,
+		&eventBridgeTarget{Trigger: trigger},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	out["RuleName"] = trigger.RuleName
+	out["RuleArn"] = ruleArn
+	out["StatementId"] = permission.StatementId
+	return out, nil
+}
+
+// GetFunctionInput returns the CreateFunctionInput of the function
+func (input eventBridgeCreateFunctionInput) GetFunctionInput() *lambda.CreateFunctionInput {
+	return input.FunctionInput
+}
+
+// eventBridgeTriggerTarget returns the ARN invoked by the rule, the alias
+// when triggers are bound to it
+func eventBridgeTriggerTarget(d *schema.ResourceData) string {
+	if d.Get("alias.0.bind_triggers").(bool) {
+		return d.Get("alias.0.arn").(string)
+	}
+	return d.Get("arn").(string)
+}
+
+// updateEventBridgeTrigger applies the changes of the event block to the
+// rule and its target
+func updateEventBridgeTrigger(conn *eventbridge.EventBridge, d *schema.ResourceData) error {
+	if !d.HasChange("event") {
+		return nil
+	}
+	trigger := expandEventBridgeTrigger(d, eventBridgeTriggerTarget(d))
+
+	if _, err := putEventBridgeRule(conn, trigger); err != nil {
+		return err
+	}
+	return putEventBridgeTarget(conn, trigger)
+}
+
+// readEventBridgeTrigger refreshes the event block from the rule and the
+// target of the function
+func readEventBridgeTrigger(conn *eventbridge.EventBridge, d *schema.ResourceData) error {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	trigger := expandEventBridgeTrigger(d, eventBridgeTriggerTarget(d))
+
+	rule, err := conn.DescribeRule(&eventbridge.DescribeRuleInput{
+		Name:         aws.String(trigger.RuleName),
+		EventBusName: aws.String(trigger.BusName),
+	})
+	if err != nil {
+		if !isAWSErr(err, eventbridge.ErrCodeResourceNotFoundException, "") {
+			return fmt.Errorf("Error reading EventBridge rule %s: %s", trigger.RuleName, err)
+		}
+		log.Printf("[WARN] EventBridge rule %s not found", trigger.RuleName)
+		rule = &eventbridge.DescribeRuleOutput{}
+	}
+
+	event["rule_name"] = trigger.RuleName
+	event["rule_arn"] = aws.StringValue(rule.Arn)
+	event["event_pattern"] = eventPatternStateFunc(aws.StringValue(rule.EventPattern))
+	event["description"] = aws.StringValue(rule.Description)
+	event["statement_id"] = eventBridgeStatementId(trigger.BusName, trigger.RuleName)
+
+	var target *eventbridge.Target
+	if rule.Arn != nil {
+		out, err := conn.ListTargetsByRule(&eventbridge.ListTargetsByRuleInput{
+			Rule:         aws.String(trigger.RuleName),
+			EventBusName: aws.String(trigger.BusName),
+		})
+		if err != nil {
+			return fmt.Errorf("Error reading targets of EventBridge rule %s: %s", trigger.RuleName, err)
+		}
+		for _, t := range out.Targets {
+			if aws.StringValue(t.Id) == aws.StringValue(trigger.Target.Id) {
+				target = t
+			}
+		}
+	}
+	if target == nil {
+		log.Printf("[WARN] Target %s of EventBridge rule %s not found", aws.StringValue(trigger.Target.Id), trigger.RuleName)
+		target = &eventbridge.Target{}
+	}
+
+	event["dead_letter_arn"] = ""
+	if target.DeadLetterConfig != nil {
+		event["dead_letter_arn"] = aws.StringValue(target.DeadLetterConfig.Arn)
+	}
+	if target.RetryPolicy != nil {
+		event["maximum_retry_attempts"] = int(aws.Int64Value(target.RetryPolicy.MaximumRetryAttempts))
+		event["maximum_event_age_in_seconds"] = int(aws.Int64Value(target.RetryPolicy.MaximumEventAgeInSeconds))
+	}
+	event["input_transformer"] = []interface{}{}
+	if target.InputTransformer != nil {
+		event["input_transformer"] = []interface{}{
+			map[string]interface{}{
+				"input_paths":    aws.StringValueMap(target.InputTransformer.InputPathsMap),
+				"input_template": aws.StringValue(target.InputTransformer.InputTemplate),
+			},
+		}
+	}
+
+	if err := d.Set("event", []interface{}{event}); err != nil {
+		return fmt.Errorf("Error setting event: %s", err)
+	}
+	return nil
+}
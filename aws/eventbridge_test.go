@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestNormalizeEventPattern(t *testing.T) {
+	testCases := []struct {
+		Pattern  string
+		Expected string
+		Error    bool
+	}{
+		{
+			Pattern:  `{"source": ["aws.ec2"]}`,
+			Expected: `{"source":["aws.ec2"]}`,
+		},
+		{
+			Pattern: `{
+				"source": ["aws.ec2"],
+				"detail-type": ["EC2 Instance State-change Notification"]
+			}`,
+			Expected: `{"detail-type":["EC2 Instance State-change Notification"],"source":["aws.ec2"]}`,
+		},
+		{
+			Pattern:  `{"detail": {"size": [{"numeric": [">", 12345678901234567890]}]}}`,
+			Expected: `{"detail":{"size":[{"numeric":[">",12345678901234567890]}]}}`,
+		},
+		{
+			Pattern:  `{"detail": {"title": ["<R&D>"]}}`,
+			Expected: `{"detail":{"title":["<R&D>"]}}`,
+		},
+		{Pattern: `["aws.ec2"]`, Error: true},
+		{Pattern: `{"source": ["aws.ec2"]} {}`, Error: true},
+		{Pattern: `{"source": `, Error: true},
+	}
+
+	for _, testCase := range testCases {
+		pattern, err := normalizeEventPattern(testCase.Pattern)
+		if testCase.Error {
+			if err == nil {
+				t.Errorf("%s: expected error", testCase.Pattern)
+			}
+			if _, errors := validateEventPattern(testCase.Pattern, "event_pattern"); len(errors) == 0 {
+				t.Errorf("%s: expected validation error", testCase.Pattern)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", testCase.Pattern, err)
+		}
+		if pattern != testCase.Expected {
+			t.Errorf("%s: expected %s, got %s", testCase.Pattern, testCase.Expected, pattern)
+		}
+	}
+}
+
+func TestEventBridgeRuleArn(t *testing.T) {
+	functionArn := "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction:live"
+
+	testCases := []struct {
+		Bus      string
+		Expected string
+	}{
+		{Bus: "default", Expected: "arn:aws:events:eu-west-1:123456789012:rule/TestRule"},
+		{Bus: "orders", Expected: "arn:aws:events:eu-west-1:123456789012:rule/orders/TestRule"},
+	}
+
+	for _, testCase := range testCases {
+		if got := eventBridgeRuleArn(functionArn, testCase.Bus, "TestRule"); got != testCase.Expected {
+			t.Errorf("%s: expected %s, got %s", testCase.Bus, testCase.Expected, got)
+		}
+	}
+}
+
+func TestEventBridgeStatementId(t *testing.T) {
+	if got := eventBridgeStatementId("default", "orders.created"); got != "EventBridge_default_orders_created" {
+		t.Errorf("bad statement id: %s", got)
+	}
+	long := eventBridgeStatementId("default", string(make([]byte, 200)))
+	if len(long) != 100 {
+		t.Errorf("expected statement id of 100 characters, got %d", len(long))
+	}
+}
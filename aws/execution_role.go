@@ -0,0 +1,341 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// executionRolePolicyName is the inline policy holding the trigger-derived
+// and user statements of a generated role
+const executionRolePolicyName = "serverless-execution"
+
+// executionRoleNamePrefixLength leaves room for the unique suffix in the
+// 64 characters of a role name
+const executionRoleNamePrefixLength = 64 - resource.UniqueIDSuffixLength - 1
+
+// iamPolicyDocument is an IAM policy
+type iamPolicyDocument struct {
+	Version   string
+	Statement []iamPolicyStatement
+}
+
+// iamPolicyStatement is a statement of an IAM policy
+type iamPolicyStatement struct {
+	Effect    string
+	Action    []string               `json:",omitempty"`
+	Resource  []string               `json:",omitempty"`
+	Principal map[string]interface{} `json:",omitempty"`
+}
+
+// iamStatementsSchema are the extra statements of the generated role
+func iamStatementsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"effect": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "Allow",
+					ValidateFunc: validation.StringInSlice([]string{"Allow", "Deny"}, false),
+				},
+				"actions": {
+					Type:     schema.TypeList,
+					Required: true,
+					MinItems: 1,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"resources": {
+					Type:     schema.TypeList,
+					Required: true,
+					MinItems: 1,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+// executionRoleConfigured reports whether role is set in the configuration,
+// rather than holding the ARN of the generated role
+func executionRoleConfigured(role, generatedRoleName string) bool {
+	return role != "" && (generatedRoleName == "" || !strings.HasSuffix(role, ":role/"+generatedRoleName))
+}
+
+// suppressGeneratedRoleDiff hides the generated role when role is not set
+// in the configuration
+func suppressGeneratedRoleDiff(k, old, new string, d *schema.ResourceData) bool {
+	return new == "" && old != "" && !executionRoleConfigured(old, d.Get("generated_role_name").(string))
+}
+
+// customizeDiffExecutionRole tracks whether role is set in the
+// configuration: removing it generates a role, setting it deletes the
+// generated one. The extra statements need a generated role to go to.
+func customizeDiffExecutionRole(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("role") {
+		return nil
+	}
+	generatedRoleName, _ := d.GetChange("generated_role_name")
+	configured := executionRoleConfigured(d.Get("role").(string), generatedRoleName.(string))
+
+	if _, ok := d.GetOk("iam_statements"); ok && configured {
+		return fmt.Errorf("iam_statements requires the execution role to be generated, remove role")
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+	if configured && generatedRoleName.(string) != "" {
+		return d.SetNew("generated_role_name", "")
+	}
+	if !configured && generatedRoleName.(string) == "" {
+		return d.SetNewComputed("generated_role_name")
+	}
+	return nil
+}
+
+// executionRoleManagedPolicies returns the managed policies attached to a
+// generated role: basic logging, plus VPC access when vpc_config is set and
+// EFS access when file_system_config is set
+func executionRoleManagedPolicies(d *schema.ResourceData) []string {
+	prefix := "arn:" + currentPartition() + ":iam::aws:policy/"
+	policies := []string{prefix + "service-role/AWSLambdaBasicExecutionRole"}
+	if v, ok := d.GetOk("vpc_config"); ok && len(v.([]interface{})) > 0 {
+		policies = append(policies, prefix+"service-role/AWSLambdaVPCAccessExecutionRole")
+	}
+	if v, ok := d.GetOk("file_system_config"); ok && len(v.([]interface{})) > 0 {
+		policies = append(policies, prefix+"AmazonElasticFileSystemClientReadWriteAccess")
+	}
+	return policies
+}
+
+// expandIamStatements returns the statements of the iam_statements list
+func expandIamStatements(l []interface{}) []iamPolicyStatement {
+	statements := make([]iamPolicyStatement, 0, len(l))
+	for _, v := range l {
+		statement := v.(map[string]interface{})
+		statements = append(statements, iamPolicyStatement{
+			Effect:   statement["effect"].(string),
+			Action:   expandStringValueList(statement["actions"].([]interface{})),
+			Resource: expandStringValueList(statement["resources"].([]interface{})),
+		})
+	}
+	return statements
+}
+
+// executionRolePolicy returns the inline policy of a generated role, or an
+// empty string when there is nothing to allow
+func executionRolePolicy(statements []iamPolicyStatement) (string, error) {
+	if len(statements) == 0 {
+		return "", nil
+	}
+	policy, err := json.Marshal(iamPolicyDocument{
+		Version:   "2012-10-17",
+		Statement: statements,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(policy), nil
+}
+
+// executionRoleAssumePolicy lets Lambda assume the generated role
+func executionRoleAssumePolicy() string {
+	policy, _ := json.Marshal(iamPolicyDocument{
+		Version: "2012-10-17",
+		Statement: []iamPolicyStatement{
+			{
+				Effect:    "Allow",
+				Action:    []string{"sts:AssumeRole"},
+				Principal: map[string]interface{}{"Service": "lambda.amazonaws.com"},
+			},
+		},
+	})
+	return string(policy)
+}
+
+// executionRoleNamePrefix returns the prefix of the generated role name
+func executionRoleNamePrefix(functionName string) string {
+	prefix := functionName + "-"
+	if len(prefix) > executionRoleNamePrefixLength {
+		prefix = prefix[:executionRoleNamePrefixLength]
+	}
+	return prefix
+}
+
+// createExecutionRole generates the execution role when role is not set,
+// triggerStatements are the permissions needed to handle the trigger events
+func createExecutionRole(conn *iam.IAM, d *schema.ResourceData, triggerStatements []iamPolicyStatement) error {
+	if d.Get("role").(string) != "" {
+		return nil
+	}
+
+	name := resource.PrefixedUniqueId(executionRoleNamePrefix(d.Get("function_name").(string)))
+	log.Printf("[DEBUG] Creating execution role %s", name)
+	out, err := conn.CreateRole(&iam.CreateRoleInput{
+		RoleName:                 aws.String(name),
+		AssumeRolePolicyDocument: aws.String(executionRoleAssumePolicy()),
+		Description:              aws.String("Execution role of Lambda function " + d.Get("function_name").(string)),
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating execution role %s: %s", name, err)
+	}
+	d.Set("generated_role_name", name)
+	d.Set("role", aws.StringValue(out.Role.Arn))
+
+	return putExecutionRolePolicies(conn, d, name, triggerStatements)
+}
+
+// putExecutionRolePolicies attaches the managed policies and puts the
+// inline policy of a generated role
+func putExecutionRolePolicies(conn *iam.IAM, d *schema.ResourceData, name string, triggerStatements []iamPolicyStatement) error {
+	for _, policyArn := range executionRoleManagedPolicies(d) {
+		_, err := conn.AttachRolePolicy(&iam.AttachRolePolicyInput{
+			RoleName:  aws.String(name),
+			PolicyArn: aws.String(policyArn),
+		})
+		if err != nil {
+			return fmt.Errorf("Error attaching %s to execution role %s: %s", policyArn, name, err)
+		}
+	}
+
+	statements := append(triggerStatements, expandIamStatements(d.Get("iam_statements").([]interface{}))...)
+	policy, err := executionRolePolicy(statements)
+	if err != nil {
+		return fmt.Errorf("Error building policy of execution role %s: %s", name, err)
+	}
+
+	if policy == "" {
+		_, err := conn.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+			RoleName:   aws.String(name),
+			PolicyName: aws.String(executionRolePolicyName),
+		})
+		if err != nil && !isAWSErr(err, iam.ErrCodeNoSuchEntityException, "") {
+			return fmt.Errorf("Error deleting policy of execution role %s: %s", name, err)
+		}
+		return nil
+	}
+
+	_, err = conn.PutRolePolicy(&iam.PutRolePolicyInput{
+		RoleName:       aws.String(name),
+		PolicyName:     aws.String(executionRolePolicyName),
+		PolicyDocument: aws.String(policy),
+	})
+	if err != nil {
+		return fmt.Errorf("Error putting policy of execution role %s: %s", name, err)
+	}
+	return nil
+}
+
+// updateExecutionRole generates the role when role is removed from the
+// configuration, and refreshes the policies of a generated role when the
+// configuration they derive from changes
+func updateExecutionRole(conn *iam.IAM, d *schema.ResourceData, triggerStatements []iamPolicyStatement) error {
+	if d.HasChange("role") && d.Get("role").(string) == "" {
+		return createExecutionRole(conn, d, triggerStatements)
+	}
+
+	name := d.Get("generated_role_name").(string)
+	if name == "" || !d.HasChange("iam_statements") && !d.HasChange("vpc_config") && !d.HasChange("file_system_config") && !d.HasChange("event") {
+		return nil
+	}
+
+	if d.HasChange("vpc_config") || d.HasChange("file_system_config") {
+		if err := detachExecutionRolePolicies(conn, name); err != nil {
+			return err
+		}
+	}
+	return putExecutionRolePolicies(conn, d, name, triggerStatements)
+}
+
+// detachExecutionRolePolicies detaches every managed policy of the role
+func detachExecutionRolePolicies(conn *iam.IAM, name string) error {
+	var policyArns []*string
+	err := conn.ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(name),
+	}, func(page *iam.ListAttachedRolePoliciesOutput, lastPage bool) bool {
+		for _, policy := range page.AttachedPolicies {
+			policyArns = append(policyArns, policy.PolicyArn)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing policies of execution role %s: %w", name, err)
+	}
+
+	for _, policyArn := range policyArns {
+		_, err := conn.DetachRolePolicy(&iam.DetachRolePolicyInput{
+			RoleName:  aws.String(name),
+			PolicyArn: policyArn,
+		})
+		if err != nil && !isAWSErr(err, iam.ErrCodeNoSuchEntityException, "") {
+			return fmt.Errorf("Error detaching %s from execution role %s: %s", aws.StringValue(policyArn), name, err)
+		}
+	}
+	return nil
+}
+
+// deleteExecutionRole deletes the generated role, if any, with its policies
+func deleteExecutionRole(conn *iam.IAM, d *schema.ResourceData) error {
+	name := d.Get("generated_role_name").(string)
+	if name == "" {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Deleting execution role %s", name)
+	if err := detachExecutionRolePolicies(conn, name); err != nil {
+		if isAWSErr(err, iam.ErrCodeNoSuchEntityException, "") {
+			d.Set("generated_role_name", "")
+			return nil
+		}
+		return err
+	}
+
+	_, err := conn.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+		RoleName:   aws.String(name),
+		PolicyName: aws.String(executionRolePolicyName),
+	})
+	if err != nil && !isAWSErr(err, iam.ErrCodeNoSuchEntityException, "") {
+		return fmt.Errorf("Error deleting policy of execution role %s: %s", name, err)
+	}
+
+	_, err = conn.DeleteRole(&iam.DeleteRoleInput{
+		RoleName: aws.String(name),
+	})
+	if err != nil && !isAWSErr(err, iam.ErrCodeNoSuchEntityException, "") {
+		return fmt.Errorf("Error deleting execution role %s: %s", name, err)
+	}
+	d.Set("generated_role_name", "")
+	return nil
+}
+
+// deleteReplacedExecutionRole deletes the generated role once the function
+// uses the role set in the configuration instead
+func deleteReplacedExecutionRole(conn *iam.IAM, d *schema.ResourceData) error {
+	o, n := d.GetChange("generated_role_name")
+	if o.(string) == "" || n.(string) != "" {
+		return nil
+	}
+	d.Set("generated_role_name", o)
+	return deleteExecutionRole(conn, d)
+}
+
+// rollbackExecutionRole deletes the generated role of a function that
+// failed to be created
+func rollbackExecutionRole(conn *iam.IAM, d *schema.ResourceData) {
+	if d.Id() != "" {
+		return
+	}
+	if err := deleteExecutionRole(conn, d); err != nil {
+		log.Printf("[ERROR] Unable to delete execution role %s: %s", d.Get("generated_role_name").(string), err)
+	}
+}
@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestExecutionRolePolicy(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Statements []iamPolicyStatement
+		Expected   string
+	}{
+		{
+			Name: "empty",
+		},
+		{
+			Name: "statements",
+			Statements: []iamPolicyStatement{
+				{
+					Effect:   "Allow",
+					Action:   []string{"s3:GetObject"},
+					Resource: []string{"arn:aws:s3:::bucket/uploads/*"},
+				},
+			},
+			Expected: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/uploads/*"]}]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := executionRolePolicy(testCase.Statements)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != testCase.Expected {
+				t.Fatalf("expected %s, got %s", testCase.Expected, got)
+			}
+		})
+	}
+}
+
+func TestExecutionRoleAssumePolicy(t *testing.T) {
+	expected := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["sts:AssumeRole"],"Principal":{"Service":"lambda.amazonaws.com"}}]}`
+	if got := executionRoleAssumePolicy(); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestExecutionRoleNamePrefix(t *testing.T) {
+	if got := executionRoleNamePrefix("TestFunction"); got != "TestFunction-" {
+		t.Fatalf("expected TestFunction-, got %s", got)
+	}
+
+	got := executionRoleNamePrefix(strings.Repeat("f", 64))
+	if len(got)+resource.UniqueIDSuffixLength > 64 {
+		t.Fatalf("prefix %s leaves no room for the unique suffix", got)
+	}
+}
+
+func TestExecutionRoleConfigured(t *testing.T) {
+	testCases := []struct {
+		Role              string
+		GeneratedRoleName string
+		Expected          bool
+	}{
+		{Role: "", GeneratedRoleName: "", Expected: false},
+		{Role: "arn:aws:iam::123456789012:role/LambdaTestRole", GeneratedRoleName: "", Expected: true},
+		{Role: "arn:aws:iam::123456789012:role/TestFunction-2021", GeneratedRoleName: "TestFunction-2021", Expected: false},
+		{Role: "arn:aws:iam::123456789012:role/LambdaTestRole", GeneratedRoleName: "TestFunction-2021", Expected: true},
+	}
+
+	for _, testCase := range testCases {
+		if got := executionRoleConfigured(testCase.Role, testCase.GeneratedRoleName); got != testCase.Expected {
+			t.Errorf("%q, %q: expected %t, got %t", testCase.Role, testCase.GeneratedRoleName, testCase.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+var (
+	efsAccessPointArnRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:elasticfilesystem:[a-z0-9-]+:\d{12}:access-point/fsap-[a-f0-9]{8,40}$`)
+	localMountPathRegexp    = regexp.MustCompile(`^/mnt/[a-zA-Z0-9-_.]+$`)
+)
+
+// fileSystemConfigSchema is the EFS access point mounted by the function
+func fileSystemConfigSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"arn": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringMatch(efsAccessPointArnRegexp, "must be the ARN of an EFS access point"),
+				},
+				"local_mount_path": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringMatch(localMountPathRegexp, "must be a directory directly under /mnt, like /mnt/models"),
+				},
+			},
+		},
+	}
+}
+
+// customizeDiffFileSystemConfig checks that the file system is reachable:
+// EFS is only mounted by functions connected to a VPC
+func customizeDiffFileSystemConfig(d *schema.ResourceDiff, m interface{}) error {
+	if _, ok := d.GetOk("file_system_config"); !ok {
+		return nil
+	}
+	if _, ok := d.GetOk("vpc_config"); !ok {
+		return fmt.Errorf("file_system_config requires vpc_config, EFS is only reachable from a VPC")
+	}
+	return nil
+}
+
+// expandFileSystemConfigs returns the file_system_config block, an empty
+// list when not set so that updates detach the file system
+func expandFileSystemConfigs(d resourceConfig) []*lambda.FileSystemConfig {
+	configs := []*lambda.FileSystemConfig{}
+	v, ok := d.GetOk("file_system_config")
+	if !ok {
+		return configs
+	}
+
+	for _, c := range v.([]interface{}) {
+		config := c.(map[string]interface{})
+		configs = append(configs, &lambda.FileSystemConfig{
+			Arn:            aws.String(config["arn"].(string)),
+			LocalMountPath: aws.String(config["local_mount_path"].(string)),
+		})
+	}
+	return configs
+}
+
+// flattenFileSystemConfigs returns the file_system_config block of the
+// function configuration
+func flattenFileSystemConfigs(configs []*lambda.FileSystemConfig) []interface{} {
+	l := make([]interface{}, 0, len(configs))
+	for _, config := range configs {
+		l = append(l, map[string]interface{}{
+			"arn":              aws.StringValue(config.Arn),
+			"local_mount_path": aws.StringValue(config.LocalMountPath),
+		})
+	}
+	return l
+}
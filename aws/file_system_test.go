@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestFileSystemConfigValidation(t *testing.T) {
+	testCases := []struct {
+		Attribute string
+		Value     string
+		Error     bool
+	}{
+		{Attribute: "arn", Value: "arn:aws:elasticfilesystem:eu-west-1:123456789012:access-point/fsap-0123456789abcdef0"},
+		{Attribute: "arn", Value: "arn:aws:elasticfilesystem:eu-west-1:123456789012:file-system/fs-01234567", Error: true},
+		{Attribute: "local_mount_path", Value: "/mnt/models"},
+		{Attribute: "local_mount_path", Value: "/mnt", Error: true},
+		{Attribute: "local_mount_path", Value: "/mnt/models/v1", Error: true},
+		{Attribute: "local_mount_path", Value: "/tmp/models", Error: true},
+	}
+
+	fields := fileSystemConfigSchema().Elem.(*schema.Resource).Schema
+	for _, testCase := range testCases {
+		_, errors := fields[testCase.Attribute].ValidateFunc(testCase.Value, testCase.Attribute)
+		if testCase.Error != (len(errors) > 0) {
+			t.Errorf("%s %q: expected error %t, got %v", testCase.Attribute, testCase.Value, testCase.Error, errors)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// functionSchema returns the attributes shared by every function resource,
+// event is the schema of the trigger specific "event" block
+func functionSchema(event *schema.Resource) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"filename": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"s3_bucket", "s3_key", "s3_object_version", "source_dir", "build"},
+		},
+		"s3_bucket": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"filename", "source_dir", "build"},
+		},
+		"s3_key": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"filename", "source_dir", "build"},
+		},
+		"s3_object_version": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"filename", "source_dir", "build"},
+		},
+		"s3_object_etag": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"source_dir": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"filename", "s3_bucket", "s3_key", "s3_object_version", "build"},
+		},
+		"source_include": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"source_exclude": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"build": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			MaxItems:      1,
+			ConflictsWith: []string{"filename", "s3_bucket", "s3_key", "s3_object_version", "source_dir"},
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"language": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringInSlice(validBuildLanguages, false),
+					},
+					"package": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"working_dir": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"goos": {
+						Type:     schema.TypeString,
+						Optional: true,
+						Default:  "linux",
+					},
+					"goarch": {
+						Type:     schema.TypeString,
+						Optional: true,
+						Default:  "amd64",
+					},
+					"ldflags": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"tags": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"memory_size": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      128,
+			ValidateFunc: validateMemorySize,
+		},
+		"runtime": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateRuntime,
+		},
+		"package_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      lambda.PackageTypeZip,
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice(validPackageTypes, false),
+		},
+		"image_uri": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"image_config":       imageConfigSchema(),
+		"file_system_config": fileSystemConfigSchema(),
+		"environment": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"variables": {
+						Type:         schema.TypeMap,
+						Optional:     true,
+						Elem:         &schema.Schema{Type: schema.TypeString},
+						ValidateFunc: validateEnvironmentVariables,
+					},
+				},
+			},
+		},
+		"timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      3,
+			ValidateFunc: validateTimeout,
+		},
+		"vpc_config": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"subnet_ids": {
+						Type:     schema.TypeSet,
+						Required: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+						Set:      schema.HashString,
+					},
+					"security_group_ids": {
+						Type:     schema.TypeSet,
+						Required: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+						Set:      schema.HashString,
+					},
+					"vpc_id": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+		"layers": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 5,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"dead_letter_config": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"target_arn": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+				},
+			},
+		},
+		"tracing_config": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"mode": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: validation.StringInSlice([]string{lambda.TracingModeActive, lambda.TracingModePassThrough}, false),
+					},
+				},
+			},
+		},
+		"kms_key_arn": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"function_name": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validateFunctionName,
+		},
+		"handler": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"arn": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"version": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"qualified_arn": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"reserved_concurrent_executions": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      -1,
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+		"provisioned_concurrency": provisionedConcurrencySchema(),
+		"async_config":            asyncConfigSchema(),
+		"logging":                 loggingSchema(),
+		"alias":                   aliasSchema(),
+		"deployment_preference":   deploymentPreferenceSchema(),
+		"last_modified": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"source_code_hash": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"source_code_size": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"publish": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"role": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     validateRoleArn,
+			DiffSuppressFunc: suppressGeneratedRoleDiff,
+		},
+		"generated_role_name": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"iam_statements": iamStatementsSchema(),
+		"event": {
+			Type:     schema.TypeList,
+			Required: true,
+			MinItems: 1,
+			MaxItems: 1,
+			Elem:     event,
+		},
+	}
+}
+
+// functionTimeouts are the timeouts shared by every function resource. The
+// update one bounds the traffic shifting of the deployment preference, the
+// default leaves room for the longest deployment type.
+func functionTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(20 * time.Minute),
+		Update: schema.DefaultTimeout(2 * time.Hour),
+	}
+}
+
+// customizeDiffFunction is the CustomizeDiff shared by every function resource
+func customizeDiffFunction() schema.CustomizeDiffFunc {
+	return customdiff.All(
+		customizeDiffFunctionCode,
+		customizeDiffFunctionVersion,
+		customizeDiffDeploymentPreference,
+		customizeDiffExecutionRole,
+		customizeDiffHandler,
+		customizeDiffRuntime,
+		customizeDiffPackageType,
+		customizeDiffFileSystemConfig,
+	)
+}
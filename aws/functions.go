@@ -1,34 +1,23 @@
 package aws
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alessandromr/go-aws-serverless/services/function"
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	homedir "github.com/mitchellh/go-homedir"
-	"io/ioutil"
 )
 
-var validLambdaRuntimes = []string{
-	lambda.RuntimeDotnetcore10,
-	lambda.RuntimeDotnetcore20,
-	lambda.RuntimeDotnetcore21,
-	lambda.RuntimeGo1X,
-	lambda.RuntimeJava8,
-	lambda.RuntimeJava11,
-	// lambda.RuntimeNodejs43, EOL
-	// lambda.RuntimeNodejs43Edge, EOL
-	// lambda.RuntimeNodejs610, EOL
-	lambda.RuntimeNodejs810,
-	lambda.RuntimeNodejs10X,
-	lambda.RuntimeNodejs12X,
-	lambda.RuntimeProvided,
-	lambda.RuntimePython27,
-	lambda.RuntimePython36,
-	lambda.RuntimePython37,
-	lambda.RuntimePython38,
-	lambda.RuntimeRuby25,
-}
-
 // Takes the result of flatmap.Expand for an array of strings
 // and returns a []*string
 func expandStringList(configured []interface{}) []*string {
@@ -42,11 +31,34 @@ func expandStringList(configured []interface{}) []*string {
 	return vs
 }
 
+// Takes the result of flatmap.Expand for an array of strings
+// and returns a []string
+func expandStringValueList(configured []interface{}) []string {
+	vs := make([]string, 0, len(configured))
+	for _, v := range configured {
+		val, ok := v.(string)
+		if ok && val != "" {
+			vs = append(vs, val)
+		}
+	}
+	return vs
+}
+
 // Takes the result of schema.Set of strings and returns a []*string
 func expandStringSet(configured *schema.Set) []*string {
 	return expandStringList(configured.List())
 }
 
+// Takes the result of flatmap.Expand for a map of strings
+// and returns a map[string]*string
+func expandStringMap(configured map[string]interface{}) map[string]*string {
+	vs := make(map[string]*string, len(configured))
+	for k, v := range configured {
+		vs[k] = aws.String(v.(string))
+	}
+	return vs
+}
+
 func readEnvironmentVariables(ev map[string]interface{}) map[string]string {
 	variables := make(map[string]string)
 	for k, v := range ev {
@@ -68,3 +80,858 @@ func loadFileContent(v string) ([]byte, error) {
 	}
 	return fileContent, nil
 }
+
+// functionStateTimeout is how long to wait for a function to become active
+// or for an update to complete
+const functionStateTimeout = 10 * time.Minute
+
+// resourceConfig is implemented by both schema.ResourceData and
+// schema.ResourceDiff, so that the function code can be read at plan time
+type resourceConfig interface {
+	Get(string) interface{}
+	GetOk(string) (interface{}, bool)
+}
+
+// buildSourceDirZip builds the deployment package configured by source_dir
+func buildSourceDirZip(d resourceConfig) ([]byte, error) {
+	return buildSourceZip(
+		d.Get("source_dir").(string),
+		expandStringValueList(d.Get("source_include").([]interface{})),
+		expandStringValueList(d.Get("source_exclude").([]interface{})),
+	)
+}
+
+// buildFunctionArtifact builds the deployment package configured by the build block
+func buildFunctionArtifact(d resourceConfig) ([]byte, error) {
+	build := d.Get("build").([]interface{})[0].(map[string]interface{})
+	switch language := build["language"].(string); language {
+	case "go":
+		return buildGoArtifact(expandGoBuildConfig(d))
+	default:
+		return nil, fmt.Errorf("Unsupported build language %q", language)
+	}
+}
+
+// expandFunctionCode returns the deployment package configured with image_uri,
+// filename, source_dir, build or the s3_* attributes. The returned function releases
+// the upload slot held by packages loaded into memory, and must be called
+// once the package has been sent to Lambda.
+func expandFunctionCode(d resourceConfig, config *Config) (*lambda.FunctionCode, func(), error) {
+	if imageUri, ok := d.GetOk("image_uri"); ok {
+		return &lambda.FunctionCode{
+			ImageUri: aws.String(imageUri.(string)),
+		}, func() {}, nil
+	}
+
+	filename, hasFilename := d.GetOk("filename")
+	_, hasSourceDir := d.GetOk("source_dir")
+	_, hasBuild := d.GetOk("build")
+	s3Bucket, bucketOk := d.GetOk("s3_bucket")
+	s3Key, keyOk := d.GetOk("s3_key")
+	s3ObjectVersion, versionOk := d.GetOk("s3_object_version")
+
+	if !hasFilename && !hasSourceDir && !hasBuild && !bucketOk && !keyOk && !versionOk {
+		return nil, nil, errors.New("image_uri, filename, source_dir, build or s3_* attributes must be set")
+	}
+
+	if hasFilename {
+		return config.functionCodeFromFile(filename.(string))
+	}
+
+	if hasSourceDir {
+		return config.functionCodeFromZip(func() ([]byte, error) {
+			return buildSourceDirZip(d)
+		})
+	}
+
+	if hasBuild {
+		return config.functionCodeFromZip(func() ([]byte, error) {
+			return buildFunctionArtifact(d)
+		})
+	}
+
+	if !bucketOk || !keyOk {
+		return nil, nil, errors.New("s3_bucket and s3_key must all be set while using S3 code source")
+	}
+	functionCode := &lambda.FunctionCode{
+		S3Bucket: aws.String(s3Bucket.(string)),
+		S3Key:    aws.String(s3Key.(string)),
+	}
+	if versionOk {
+		functionCode.S3ObjectVersion = aws.String(s3ObjectVersion.(string))
+	}
+	return functionCode, func() {}, nil
+}
+
+// customizeDiffFunctionCode computes source_code_hash from the local
+// deployment package (filename, source_dir or build) and tracks the ETag of S3
+// packages, so that code changes show up in the plan and trigger an update
+func customizeDiffFunctionCode(d *schema.ResourceDiff, m interface{}) error {
+	if _, ok := d.GetOk("s3_bucket"); ok {
+		return customizeDiffS3ObjectEtag(d)
+	}
+
+	var hash string
+	if filename, ok := d.GetOk("filename"); ok {
+		fileHash, err := fileSourceCodeHash(filename.(string))
+		if err != nil {
+			return fmt.Errorf("Unable to load %q: %s", filename.(string), err)
+		}
+		hash = fileHash
+	} else if _, ok := d.GetOk("source_dir"); ok {
+		file, err := buildSourceDirZip(d)
+		if err != nil {
+			return err
+		}
+		hash = sourceCodeHash(file)
+	} else if _, ok := d.GetOk("build"); ok {
+		file, err := buildFunctionArtifact(d)
+		if err != nil {
+			return err
+		}
+		hash = sourceCodeHash(file)
+	} else {
+		return nil
+	}
+
+	// A source_code_hash explicitly changed in the configuration wins
+	if d.HasChange("source_code_hash") {
+		return nil
+	}
+
+	if old, _ := d.GetChange("source_code_hash"); old.(string) != hash {
+		log.Printf("[DEBUG] Source code hash changed from %q to %q", old.(string), hash)
+		return d.SetNew("source_code_hash", hash)
+	}
+	return nil
+}
+
+// customizeDiffS3ObjectEtag tracks the ETag of the S3 deployment package, the
+// object is overwritten in place when neither key nor version change
+func customizeDiffS3ObjectEtag(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("s3_bucket") || !d.NewValueKnown("s3_key") || !d.NewValueKnown("s3_object_version") {
+		return d.SetNewComputed("s3_object_etag")
+	}
+
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(d.Get("s3_bucket").(string)),
+		Key:    aws.String(d.Get("s3_key").(string)),
+	}
+	if v, ok := d.GetOk("s3_object_version"); ok {
+		input.VersionId = aws.String(v.(string))
+	}
+
+	output, err := auth.Client.S3Conn.HeadObject(input)
+	if err != nil {
+		if isAWSErr(err, "NotFound", "") {
+			// The object is probably uploaded in the same apply
+			log.Printf("[DEBUG] S3 deployment package s3://%s/%s not found", *input.Bucket, *input.Key)
+			return d.SetNewComputed("s3_object_etag")
+		}
+		return fmt.Errorf("Error reading S3 deployment package s3://%s/%s: %s", *input.Bucket, *input.Key, err)
+	}
+
+	etag := strings.Trim(aws.StringValue(output.ETag), `"`)
+	if old, _ := d.GetChange("s3_object_etag"); old.(string) != etag {
+		log.Printf("[DEBUG] S3 deployment package ETag changed from %q to %q", old.(string), etag)
+		return d.SetNew("s3_object_etag", etag)
+	}
+	return nil
+}
+
+// resourceChanges is implemented by both schema.ResourceData and
+// schema.ResourceDiff, so that changes can be detected at plan time
+type resourceChanges interface {
+	HasChange(string) bool
+}
+
+// functionConfigurationKeys are the attributes applied with
+// UpdateFunctionConfiguration
+var functionConfigurationKeys = []string{
+	"description",
+	"handler",
+	"runtime",
+	"memory_size",
+	"timeout",
+	"role",
+	"environment",
+	"vpc_config",
+	"layers",
+	"dead_letter_config",
+	"tracing_config",
+	"kms_key_arn",
+	"image_config",
+	"file_system_config",
+}
+
+// functionConfigurationChanged reports whether the function configuration
+// must be updated
+func functionConfigurationChanged(d resourceChanges) bool {
+	for _, key := range functionConfigurationKeys {
+		if d.HasChange(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// functionCodeChanged reports whether the deployment package must be uploaded again
+func functionCodeChanged(d resourceChanges) bool {
+	return d.HasChange("source_code_hash") ||
+		d.HasChange("filename") ||
+		d.HasChange("source_dir") ||
+		d.HasChange("build") ||
+		d.HasChange("s3_bucket") ||
+		d.HasChange("s3_key") ||
+		d.HasChange("s3_object_version") ||
+		d.HasChange("s3_object_etag") ||
+		d.HasChange("image_uri")
+}
+
+// updateFunctionCode uploads the deployment package when it changed
+func updateFunctionCode(conn *lambda.Lambda, d *schema.ResourceData, config *Config) error {
+	if !functionCodeChanged(d) {
+		return nil
+	}
+
+	functionCode, release, err := expandFunctionCode(d, config)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	input := &lambda.UpdateFunctionCodeInput{
+		FunctionName:    aws.String(d.Id()),
+		Publish:         aws.Bool(d.Get("publish").(bool)),
+		ZipFile:         functionCode.ZipFile,
+		S3Bucket:        functionCode.S3Bucket,
+		S3Key:           functionCode.S3Key,
+		S3ObjectVersion: functionCode.S3ObjectVersion,
+		ImageUri:        functionCode.ImageUri,
+	}
+
+	log.Printf("[DEBUG] Updating Lambda function code: %s", d.Id())
+	out, err := conn.UpdateFunctionCode(input)
+	release()
+	if err != nil {
+		return fmt.Errorf("Error updating Lambda function code: %s", err)
+	}
+	if input.Publish != nil && *input.Publish {
+		d.Set("version", out.Version)
+	}
+	return waitForFunctionUpdated(conn, d.Id())
+}
+
+// updateFunctionConfiguration applies the configuration changes with a
+// single UpdateFunctionConfiguration and waits for the update to complete
+func updateFunctionConfiguration(conn *lambda.Lambda, d *schema.ResourceData) error {
+	input := &lambda.UpdateFunctionConfigurationInput{
+		FunctionName: aws.String(d.Id()),
+	}
+	changed := false
+
+	if d.HasChange("description") {
+		input.Description = aws.String(d.Get("description").(string))
+		changed = true
+	}
+
+	if d.HasChange("handler") {
+		input.Handler = aws.String(d.Get("handler").(string))
+		changed = true
+	}
+
+	if d.HasChange("runtime") {
+		input.Runtime = aws.String(d.Get("runtime").(string))
+		changed = true
+	}
+
+	if d.HasChange("memory_size") {
+		input.MemorySize = aws.Int64(int64(d.Get("memory_size").(int)))
+		changed = true
+	}
+
+	if d.HasChange("timeout") {
+		input.Timeout = aws.Int64(int64(d.Get("timeout").(int)))
+		changed = true
+	}
+
+	if d.HasChange("role") {
+		input.Role = aws.String(d.Get("role").(string))
+		changed = true
+	}
+
+	if d.HasChange("environment") {
+		input.Environment = expandFunctionEnvironment(d)
+		changed = true
+	}
+
+	if d.HasChange("vpc_config") {
+		input.VpcConfig = expandFunctionVpcConfig(d)
+		changed = true
+	}
+
+	if d.HasChange("layers") {
+		input.Layers = expandStringList(d.Get("layers").([]interface{}))
+		changed = true
+	}
+
+	if d.HasChange("dead_letter_config") {
+		input.DeadLetterConfig = &lambda.DeadLetterConfig{
+			TargetArn: aws.String(d.Get("dead_letter_config.0.target_arn").(string)),
+		}
+		changed = true
+	}
+
+	if d.HasChange("tracing_config") {
+		if v, ok := d.GetOk("tracing_config.0.mode"); ok {
+			input.TracingConfig = &lambda.TracingConfig{
+				Mode: aws.String(v.(string)),
+			}
+			changed = true
+		}
+	}
+
+	if d.HasChange("kms_key_arn") {
+		input.KMSKeyArn = aws.String(d.Get("kms_key_arn").(string))
+		changed = true
+	}
+
+	if d.HasChange("image_config") {
+		input.ImageConfig = expandImageConfig(d)
+		if input.ImageConfig == nil {
+			// An empty configuration resets the overrides to the image defaults
+			input.ImageConfig = &lambda.ImageConfig{}
+		}
+		changed = true
+	}
+
+	if d.HasChange("file_system_config") {
+		input.FileSystemConfigs = expandFileSystemConfigs(d)
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Updating Lambda function configuration: %s", input)
+	// A role generated by this update may not have propagated yet
+	err := resource.Retry(1*time.Minute, func() *resource.RetryError {
+		_, err := conn.UpdateFunctionConfiguration(input)
+		if err != nil {
+			if isAWSErr(err, "InvalidParameterValueException", "The role defined for the function cannot be assumed by Lambda") {
+				log.Printf("[DEBUG] Received %s, retrying UpdateFunctionConfiguration", err)
+				return resource.RetryableError(err)
+			}
+			if isAWSErr(err, "InvalidParameterValueException", "The provided execution role does not have permissions") {
+				log.Printf("[DEBUG] Received %s, retrying UpdateFunctionConfiguration", err)
+				return resource.RetryableError(err)
+			}
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+	if isResourceTimeoutError(err) {
+		_, err = conn.UpdateFunctionConfiguration(input)
+	}
+	if err != nil {
+		return fmt.Errorf("Error updating Lambda function configuration: %s", err)
+	}
+	if err := waitForFunctionUpdated(conn, d.Id()); err != nil {
+		return err
+	}
+
+	// A code change publishes the version along with the new package
+	if !d.Get("publish").(bool) || functionCodeChanged(d) {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Publishing Lambda function version: %s", d.Id())
+	out, err := conn.PublishVersion(&lambda.PublishVersionInput{
+		FunctionName: aws.String(d.Id()),
+	})
+	if err != nil {
+		return fmt.Errorf("Error publishing Lambda function version: %s", err)
+	}
+	d.Set("version", out.Version)
+	return nil
+}
+
+// updateFunctionTags applies the changes of the tags
+func updateFunctionTags(conn *lambda.Lambda, d *schema.ResourceData) error {
+	if !d.HasChange("tags") {
+		return nil
+	}
+
+	o, n := d.GetChange("tags")
+	create, remove := diffTagsGeneric(o.(map[string]interface{}), n.(map[string]interface{}))
+	arn := aws.String(d.Get("arn").(string))
+
+	if len(remove) > 0 {
+		keys := make([]*string, 0, len(remove))
+		for k := range remove {
+			keys = append(keys, aws.String(k))
+		}
+		if _, err := conn.UntagResource(&lambda.UntagResourceInput{Resource: arn, TagKeys: keys}); err != nil {
+			return fmt.Errorf("Error removing tags of Lambda function %s: %s", d.Id(), err)
+		}
+	}
+
+	if len(create) > 0 {
+		if _, err := conn.TagResource(&lambda.TagResourceInput{Resource: arn, Tags: create}); err != nil {
+			return fmt.Errorf("Error tagging Lambda function %s: %s", d.Id(), err)
+		}
+	}
+	return nil
+}
+
+// expandFunctionEnvironment returns the environment block, empty variables
+// when not set so that updates remove them
+func expandFunctionEnvironment(d resourceConfig) *lambda.Environment {
+	variables := map[string]string{}
+	if v, ok := d.GetOk("environment.0.variables"); ok {
+		variables = readEnvironmentVariables(v.(map[string]interface{}))
+	}
+	return &lambda.Environment{
+		Variables: aws.StringMap(variables),
+	}
+}
+
+// expandFunctionVpcConfig returns the vpc_config block, empty subnets and
+// security groups when not set so that updates detach the function
+func expandFunctionVpcConfig(d resourceConfig) *lambda.VpcConfig {
+	config := &lambda.VpcConfig{
+		SubnetIds:        []*string{},
+		SecurityGroupIds: []*string{},
+	}
+	if v, ok := d.GetOk("vpc_config"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		vpc := v.([]interface{})[0].(map[string]interface{})
+		config.SubnetIds = expandStringSet(vpc["subnet_ids"].(*schema.Set))
+		config.SecurityGroupIds = expandStringSet(vpc["security_group_ids"].(*schema.Set))
+	}
+	return config
+}
+
+// readFunctionConfiguration refreshes every attribute of the function
+// configuration, so that the changes made outside of Terraform show up in
+// the plan
+func readFunctionConfiguration(conn *lambda.Lambda, d *schema.ResourceData) error {
+	out, err := conn.GetFunction(&lambda.GetFunctionInput{
+		FunctionName: aws.String(d.Id()),
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading Lambda function %s: %w", d.Id(), err)
+	}
+	config := out.Configuration
+
+	d.Set("function_name", config.FunctionName)
+	d.Set("arn", config.FunctionArn)
+	d.Set("role", config.Role)
+	d.Set("memory_size", config.MemorySize)
+	d.Set("runtime", config.Runtime)
+	d.Set("handler", config.Handler)
+	d.Set("description", config.Description)
+	d.Set("last_modified", config.LastModified)
+	d.Set("timeout", config.Timeout)
+	d.Set("source_code_hash", config.CodeSha256)
+	d.Set("source_code_size", config.CodeSize)
+	d.Set("kms_key_arn", config.KMSKeyArn)
+
+	if err := d.Set("environment", flattenFunctionEnvironment(config.Environment)); err != nil {
+		return fmt.Errorf("Error setting environment: %s", err)
+	}
+
+	if err := d.Set("vpc_config", flattenFunctionVpcConfig(config.VpcConfig)); err != nil {
+		return fmt.Errorf("Error setting vpc_config: %s", err)
+	}
+
+	layers := make([]string, 0, len(config.Layers))
+	for _, layer := range config.Layers {
+		layers = append(layers, aws.StringValue(layer.Arn))
+	}
+	if err := d.Set("layers", layers); err != nil {
+		return fmt.Errorf("Error setting layers: %s", err)
+	}
+
+	deadLetterConfig := []interface{}{}
+	if config.DeadLetterConfig != nil && aws.StringValue(config.DeadLetterConfig.TargetArn) != "" {
+		deadLetterConfig = append(deadLetterConfig, map[string]interface{}{
+			"target_arn": aws.StringValue(config.DeadLetterConfig.TargetArn),
+		})
+	}
+	if err := d.Set("dead_letter_config", deadLetterConfig); err != nil {
+		return fmt.Errorf("Error setting dead_letter_config: %s", err)
+	}
+
+	tracingConfig := []interface{}{}
+	if config.TracingConfig != nil {
+		tracingConfig = append(tracingConfig, map[string]interface{}{
+			"mode": aws.StringValue(config.TracingConfig.Mode),
+		})
+	}
+	if err := d.Set("tracing_config", tracingConfig); err != nil {
+		return fmt.Errorf("Error setting tracing_config: %s", err)
+	}
+
+	if err := d.Set("file_system_config", flattenFileSystemConfigs(config.FileSystemConfigs)); err != nil {
+		return fmt.Errorf("Error setting file_system_config: %s", err)
+	}
+
+	if err := d.Set("tags", tagsToMapGeneric(out.Tags)); err != nil {
+		return fmt.Errorf("Error setting tags: %s", err)
+	}
+
+	return readFunctionImage(d, out)
+}
+
+// flattenFunctionEnvironment returns the environment block, empty when the
+// function has no variables
+func flattenFunctionEnvironment(environment *lambda.EnvironmentResponse) []interface{} {
+	if environment == nil || len(environment.Variables) == 0 {
+		return []interface{}{}
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"variables": aws.StringValueMap(environment.Variables),
+		},
+	}
+}
+
+// flattenFunctionVpcConfig returns the vpc_config block, empty when the
+// function is not connected to a VPC
+func flattenFunctionVpcConfig(config *lambda.VpcConfigResponse) []interface{} {
+	if config == nil || len(config.SubnetIds) == 0 {
+		return []interface{}{}
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"subnet_ids":         schema.NewSet(schema.HashString, flattenStringList(config.SubnetIds)),
+			"security_group_ids": schema.NewSet(schema.HashString, flattenStringList(config.SecurityGroupIds)),
+			"vpc_id":             aws.StringValue(config.VpcId),
+		},
+	}
+}
+
+// flattenStringList returns the []interface{} of a []*string
+func flattenStringList(l []*string) []interface{} {
+	vs := make([]interface{}, 0, len(l))
+	for _, v := range l {
+		vs = append(vs, aws.StringValue(v))
+	}
+	return vs
+}
+
+// waitForFunctionActive waits for a new function to leave the Pending state,
+// image functions in particular are not ready as soon as they are created
+func waitForFunctionActive(conn *lambda.Lambda, functionName string) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{lambda.StatePending},
+		Target:  []string{lambda.StateActive},
+		Refresh: func() (interface{}, string, error) {
+			out, err := conn.GetFunctionConfiguration(&lambda.GetFunctionConfigurationInput{
+				FunctionName: aws.String(functionName),
+			})
+			if err != nil {
+				return nil, "", err
+			}
+
+			state := aws.StringValue(out.State)
+			if state == "" {
+				// Functions created before states were introduced
+				state = lambda.StateActive
+			}
+			if state == lambda.StateFailed {
+				return out, state, fmt.Errorf("%s: %s", aws.StringValue(out.StateReasonCode), aws.StringValue(out.StateReason))
+			}
+			return out, state, nil
+		},
+		Timeout:    functionStateTimeout,
+		Delay:      time.Second,
+		MinTimeout: 2 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for Lambda function %s to become active: %s", functionName, err)
+	}
+	return nil
+}
+
+// waitForFunctionUpdated waits for the last code or configuration update of
+// the function to complete, the next one is rejected until then
+func waitForFunctionUpdated(conn *lambda.Lambda, functionName string) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{lambda.LastUpdateStatusInProgress},
+		Target:  []string{lambda.LastUpdateStatusSuccessful},
+		Refresh: func() (interface{}, string, error) {
+			out, err := conn.GetFunctionConfiguration(&lambda.GetFunctionConfigurationInput{
+				FunctionName: aws.String(functionName),
+			})
+			if err != nil {
+				return nil, "", err
+			}
+
+			status := aws.StringValue(out.LastUpdateStatus)
+			if status == "" {
+				status = lambda.LastUpdateStatusSuccessful
+			}
+			if status == lambda.LastUpdateStatusFailed {
+				return out, status, fmt.Errorf("%s: %s", aws.StringValue(out.LastUpdateStatusReasonCode), aws.StringValue(out.LastUpdateStatusReason))
+			}
+			return out, status, nil
+		},
+		Timeout:    functionStateTimeout,
+		Delay:      time.Second,
+		MinTimeout: 2 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for update of Lambda function %s: %s", functionName, err)
+	}
+	return nil
+}
+
+// expandCreateFunctionInput returns the CreateFunctionInput of the
+// attributes shared by every trigger
+func expandCreateFunctionInput(d *schema.ResourceData, code *lambda.FunctionCode) (*lambda.CreateFunctionInput, error) {
+	functionName := d.Get("function_name").(string)
+
+	funcParam := &lambda.CreateFunctionInput{
+		Code:         code,
+		Description:  aws.String(d.Get("description").(string)),
+		FunctionName: aws.String(functionName),
+		MemorySize:   aws.Int64(int64(d.Get("memory_size").(int))),
+		Role:         aws.String(d.Get("role").(string)),
+		Timeout:      aws.Int64(int64(d.Get("timeout").(int))),
+		Publish:      aws.Bool(d.Get("publish").(bool)),
+	}
+	expandFunctionPackage(d, funcParam)
+
+	if _, ok := d.GetOk("file_system_config"); ok {
+		funcParam.FileSystemConfigs = expandFileSystemConfigs(d)
+	}
+
+	if v, ok := d.GetOk("layers"); ok && len(v.([]interface{})) > 0 {
+		funcParam.Layers = expandStringList(v.([]interface{}))
+	}
+
+	if v, ok := d.GetOk("dead_letter_config"); ok {
+		dlcMaps := v.([]interface{})
+		if len(dlcMaps) == 1 { // Schema guarantees either 0 or 1
+			// Prevent panic on nil dead_letter_config. See GH-14961
+			if dlcMaps[0] == nil {
+				return nil, fmt.Errorf("Nil dead_letter_config supplied for function: %s", functionName)
+			}
+			dlcMap := dlcMaps[0].(map[string]interface{})
+			funcParam.DeadLetterConfig = &lambda.DeadLetterConfig{
+				TargetArn: aws.String(dlcMap["target_arn"].(string)),
+			}
+		}
+	}
+
+	if v, ok := d.GetOk("vpc_config"); ok && len(v.([]interface{})) > 0 {
+		config := v.([]interface{})[0].(map[string]interface{})
+
+		funcParam.VpcConfig = &lambda.VpcConfig{
+			SecurityGroupIds: expandStringSet(config["security_group_ids"].(*schema.Set)),
+			SubnetIds:        expandStringSet(config["subnet_ids"].(*schema.Set)),
+		}
+	}
+
+	if v, ok := d.GetOk("tracing_config"); ok {
+		tracingConfig := v.([]interface{})
+		tracing := tracingConfig[0].(map[string]interface{})
+		funcParam.TracingConfig = &lambda.TracingConfig{
+			Mode: aws.String(tracing["mode"].(string)),
+		}
+	}
+
+	if v, ok := d.GetOk("environment"); ok {
+		environments := v.([]interface{})
+		environment, ok := environments[0].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("At least one field is expected inside environment")
+		}
+
+		if environmentVariables, ok := environment["variables"]; ok {
+			variables := readEnvironmentVariables(environmentVariables.(map[string]interface{}))
+
+			funcParam.Environment = &lambda.Environment{
+				Variables: aws.StringMap(variables),
+			}
+		}
+	}
+
+	if v, ok := d.GetOk("kms_key_arn"); ok {
+		funcParam.KMSKeyArn = aws.String(v.(string))
+	}
+
+	if v, exists := d.GetOk("tags"); exists {
+		funcParam.Tags = tagsFromMapGeneric(v.(map[string]interface{}))
+	}
+
+	return funcParam, nil
+}
+
+// createFunctionWithRetry creates the function with createFunction. Only the
+// CreateFunction call is retried, while the execution role propagates and on
+// EC2 throttling, the waiters and the dependencies run once. The upload slot
+// of the deployment package is released as soon as CreateFunction returns.
+func createFunctionWithRetry(conn *lambda.Lambda, d *schema.ResourceData, input function.CreateFunctionInput, release func()) (map[string]interface{}, error) {
+	params := input.GetFunctionInput()
+	defer release()
+	log.Printf("[DEBUG] Creating Lambda function: %s", aws.StringValue(params.FunctionName))
+
+	var lambdaConf *lambda.FunctionConfiguration
+	err := resource.Retry(1*time.Minute, func() *resource.RetryError {
+		var intErr error
+		lambdaConf, intErr = conn.CreateFunction(params)
+
+		if intErr != nil {
+			log.Printf("[DEBUG] Error creating Lambda Function: %s", intErr)
+
+			if isAWSErr(intErr, "InvalidParameterValueException", "The role defined for the function cannot be assumed by Lambda") {
+				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
+				return resource.RetryableError(intErr)
+			}
+			if isAWSErr(intErr, "InvalidParameterValueException", "The provided execution role does not have permissions") {
+				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
+				return resource.RetryableError(intErr)
+			}
+			if isAWSErr(intErr, "InvalidParameterValueException", "Your request has been throttled by EC2") {
+				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
+				return resource.RetryableError(intErr)
+			}
+			if isAWSErr(intErr, "InvalidParameterValueException", "Lambda was unable to configure access to your environment variables because the KMS key is invalid for CreateGrant") {
+				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
+				return resource.RetryableError(intErr)
+			}
+			return resource.NonRetryableError(intErr)
+		}
+		return nil
+	})
+
+	if err != nil {
+		if !isResourceTimeoutError(err) && !isAWSErr(err, "InvalidParameterValueException", "Your request has been throttled by EC2") {
+			return nil, fmt.Errorf("Error creating Lambda function: %s", err)
+		}
+		// Allow additional time for slower uploads or EC2 throttling
+		err := resource.Retry(d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+			lambdaConf, err = conn.CreateFunction(params)
+			if err != nil {
+				log.Printf("[DEBUG] Error creating Lambda Function: %s", err)
+
+				if isAWSErr(err, "InvalidParameterValueException", "Your request has been throttled by EC2") {
+					log.Printf("[DEBUG] Received %s, retrying CreateFunction", err)
+					return resource.RetryableError(err)
+				}
+				return resource.NonRetryableError(err)
+			}
+			return nil
+		})
+		if isResourceTimeoutError(err) {
+			lambdaConf, err = conn.CreateFunction(params)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error creating Lambda function: %s", err)
+		}
+	}
+
+	// The package is no longer needed, let it be collected before the slot
+	// is taken by the next one
+	params.Code.ZipFile = nil
+	release()
+
+	return createFunction(conn, d, input, lambdaConf)
+}
+
+// createFunction waits for the new function to become active and creates
+// its alias and its trigger like function.CreateFunction does, binding the
+// trigger to the alias when configured. The function is deleted when any of
+// them fails.
+func createFunction(conn *lambda.Lambda, d *schema.ResourceData, input function.CreateFunctionInput, lambdaConf *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	err := waitForFunctionActive(conn, aws.StringValue(lambdaConf.FunctionName))
+	var out map[string]interface{}
+	if err == nil {
+		out, err = createFunctionDependencies(conn, d, input, lambdaConf)
+	}
+	if err != nil {
+		//Rollback
+		log.Printf("[DEBUG] Deleting Lambda function %s after error: %s", aws.StringValue(lambdaConf.FunctionName), err)
+		_, lerr := conn.DeleteFunction(&lambda.DeleteFunctionInput{
+			FunctionName: lambdaConf.FunctionArn,
+		})
+		if lerr != nil && !isAWSErr(lerr, "ResourceNotFoundException", "") {
+			log.Printf("[ERROR] Unable to delete Lambda function %s: %s", aws.StringValue(lambdaConf.FunctionName), lerr)
+		}
+		return nil, err
+	}
+
+	out["FunctionArn"] = aws.StringValue(lambdaConf.FunctionArn)
+	out["Version"] = aws.StringValue(lambdaConf.Version)
+	return out, nil
+}
+
+func createFunctionDependencies(conn *lambda.Lambda, d *schema.ResourceData, input function.CreateFunctionInput, lambdaConf *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	d.Set("version", lambdaConf.Version)
+
+	aliasArn, err := createFunctionAlias(conn, d)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := createFunctionConcurrency(conn, d); err != nil {
+		return nil, err
+	}
+
+	if err := createFunctionAsyncConfig(conn, d); err != nil {
+		return nil, err
+	}
+
+	target := lambdaConf
+	if v, ok := d.GetOk("alias.0.bind_triggers"); ok && v.(bool) {
+		log.Printf("[DEBUG] Binding trigger to alias %s", aliasArn)
+		target = &lambda.FunctionConfiguration{
+			FunctionArn:  aws.String(aliasArn),
+			FunctionName: lambdaConf.FunctionName,
+		}
+	}
+
+	out, err := input.CreateDependencies(target)
+	if err != nil {
+		return nil, err
+	}
+	out["AliasArn"] = aliasArn
+	return out, nil
+}
+
+// customizeDiffFunctionVersion marks the version and the qualified ARN as
+// computed when the update publishes a new version
+func customizeDiffFunctionVersion(d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.Get("publish").(bool) {
+		return nil
+	}
+	if !functionCodeChanged(d) && !functionConfigurationChanged(d) {
+		return nil
+	}
+
+	if err := d.SetNewComputed("version"); err != nil {
+		return err
+	}
+	return d.SetNewComputed("qualified_arn")
+}
+
+// readFunctionVersions sets the latest published version, the qualified ARN
+// and the alias of the function
+func readFunctionVersions(conn *lambda.Lambda, d *schema.ResourceData) error {
+	version, err := readLatestFunctionVersion(conn, d.Id())
+	if err != nil {
+		return err
+	}
+	d.Set("version", version)
+	d.Set("qualified_arn", d.Get("arn").(string)+":"+version)
+
+	return readFunctionAlias(conn, d)
+}
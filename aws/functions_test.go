@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestFlattenFunctionEnvironment(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Environment *lambda.EnvironmentResponse
+		Expected    []interface{}
+	}{
+		{
+			Name:     "nil",
+			Expected: []interface{}{},
+		},
+		{
+			Name:        "no variables",
+			Environment: &lambda.EnvironmentResponse{},
+			Expected:    []interface{}{},
+		},
+		{
+			Name: "variables",
+			Environment: &lambda.EnvironmentResponse{
+				Variables: aws.StringMap(map[string]string{"TABLE_NAME": "test"}),
+			},
+			Expected: []interface{}{
+				map[string]interface{}{
+					"variables": map[string]string{"TABLE_NAME": "test"},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := flattenFunctionEnvironment(testCase.Environment)
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Fatalf("expected %v, got %v", testCase.Expected, got)
+			}
+		})
+	}
+}
+
+func TestFlattenFunctionVpcConfig(t *testing.T) {
+	if got := flattenFunctionVpcConfig(&lambda.VpcConfigResponse{VpcId: aws.String("")}); len(got) != 0 {
+		t.Fatalf("expected no vpc_config outside of a VPC, got %v", got)
+	}
+
+	got := flattenFunctionVpcConfig(&lambda.VpcConfigResponse{
+		SubnetIds:        aws.StringSlice([]string{"subnet-1", "subnet-2"}),
+		SecurityGroupIds: aws.StringSlice([]string{"sg-1"}),
+		VpcId:            aws.String("vpc-1"),
+	})
+	if len(got) != 1 {
+		t.Fatalf("expected one vpc_config, got %v", got)
+	}
+
+	config := got[0].(map[string]interface{})
+	if subnets := config["subnet_ids"].(*schema.Set); subnets.Len() != 2 || !subnets.Contains("subnet-1") {
+		t.Errorf("unexpected subnet_ids %v", subnets.List())
+	}
+	if groups := config["security_group_ids"].(*schema.Set); groups.Len() != 1 || !groups.Contains("sg-1") {
+		t.Errorf("unexpected security_group_ids %v", groups.List())
+	}
+	if config["vpc_id"] != "vpc-1" {
+		t.Errorf("expected vpc_id vpc-1, got %v", config["vpc_id"])
+	}
+}
+
+// testResourceChanges reports the listed keys as changed
+type testResourceChanges []string
+
+func (c testResourceChanges) HasChange(key string) bool {
+	for _, k := range c {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFunctionChanged(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Changes       testResourceChanges
+		Code          bool
+		Configuration bool
+	}{
+		{Name: "no change", Changes: nil, Code: false, Configuration: false},
+		{Name: "code", Changes: testResourceChanges{"source_code_hash"}, Code: true, Configuration: false},
+		{Name: "image", Changes: testResourceChanges{"image_uri"}, Code: true, Configuration: false},
+		{Name: "configuration", Changes: testResourceChanges{"memory_size"}, Code: false, Configuration: true},
+		{Name: "both", Changes: testResourceChanges{"s3_key", "environment"}, Code: true, Configuration: true},
+		{Name: "other", Changes: testResourceChanges{"tags", "alias"}, Code: false, Configuration: false},
+	}
+
+	for _, testCase := range testCases {
+		if got := functionCodeChanged(testCase.Changes); got != testCase.Code {
+			t.Errorf("%s: expected code changed %t, got %t", testCase.Name, testCase.Code, got)
+		}
+		if got := functionConfigurationChanged(testCase.Changes); got != testCase.Configuration {
+			t.Errorf("%s: expected configuration changed %t, got %t", testCase.Name, testCase.Configuration, got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+var validPackageTypes = []string{
+	lambda.PackageTypeZip,
+	lambda.PackageTypeImage,
+}
+
+// zipCodeSources are the attributes of the deployment packages of Zip functions
+var zipCodeSources = []string{
+	"filename",
+	"s3_bucket",
+	"s3_key",
+	"s3_object_version",
+	"source_dir",
+	"build",
+}
+
+// imageConfigSchema overrides the container image settings
+func imageConfigSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"entry_point": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"command": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"working_directory": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+// customizeDiffPackageType checks the attributes required and forbidden by
+// the package type: Zip functions need a handler, a runtime and a deployment
+// package, Image functions only an image_uri
+func customizeDiffPackageType(d *schema.ResourceDiff, m interface{}) error {
+	if d.Get("package_type").(string) == lambda.PackageTypeImage {
+		if _, ok := d.GetOk("image_uri"); !ok && d.NewValueKnown("image_uri") {
+			return fmt.Errorf("package_type Image requires image_uri")
+		}
+		for _, attribute := range append([]string{"handler", "runtime"}, zipCodeSources...) {
+			if _, ok := d.GetOk(attribute); ok {
+				return fmt.Errorf("%s cannot be used with package_type Image", attribute)
+			}
+		}
+		return nil
+	}
+
+	for _, attribute := range []string{"image_uri", "image_config"} {
+		if _, ok := d.GetOk(attribute); ok {
+			return fmt.Errorf("%s requires package_type Image", attribute)
+		}
+	}
+	for _, attribute := range []string{"handler", "runtime"} {
+		if _, ok := d.GetOk(attribute); !ok && d.NewValueKnown(attribute) {
+			return fmt.Errorf("%s is required with package_type Zip", attribute)
+		}
+	}
+	for _, attribute := range zipCodeSources {
+		if _, ok := d.GetOk(attribute); ok || !d.NewValueKnown(attribute) {
+			return nil
+		}
+	}
+	return fmt.Errorf("package_type Zip requires a deployment package, one of filename, s3_bucket, source_dir or build")
+}
+
+// expandImageConfig returns the image_config block, nil when not set
+func expandImageConfig(d resourceConfig) *lambda.ImageConfig {
+	v, ok := d.GetOk("image_config")
+	if !ok || v.([]interface{})[0] == nil {
+		return nil
+	}
+	config := v.([]interface{})[0].(map[string]interface{})
+
+	imageConfig := &lambda.ImageConfig{
+		EntryPoint: expandStringList(config["entry_point"].([]interface{})),
+		Command:    expandStringList(config["command"].([]interface{})),
+	}
+	if v := config["working_directory"].(string); v != "" {
+		imageConfig.WorkingDirectory = aws.String(v)
+	}
+	return imageConfig
+}
+
+// expandFunctionPackage sets the package type of the function and the
+// attributes depending on it
+func expandFunctionPackage(d *schema.ResourceData, input *lambda.CreateFunctionInput) {
+	input.PackageType = aws.String(d.Get("package_type").(string))
+	if v, ok := d.GetOk("handler"); ok {
+		input.Handler = aws.String(v.(string))
+	}
+	if v, ok := d.GetOk("runtime"); ok {
+		input.Runtime = aws.String(v.(string))
+	}
+	input.ImageConfig = expandImageConfig(d)
+}
+
+// readFunctionImage refreshes the package type, the image and its configuration
+func readFunctionImage(d *schema.ResourceData, out *lambda.GetFunctionOutput) error {
+	packageType := lambda.PackageTypeZip
+	if out.Configuration != nil && out.Configuration.PackageType != nil {
+		packageType = aws.StringValue(out.Configuration.PackageType)
+	}
+	d.Set("package_type", packageType)
+	if packageType != lambda.PackageTypeImage {
+		return nil
+	}
+
+	if out.Code != nil {
+		d.Set("image_uri", aws.StringValue(out.Code.ImageUri))
+	}
+
+	imageConfig := []interface{}{}
+	if response := out.Configuration.ImageConfigResponse; response != nil && response.ImageConfig != nil {
+		config := response.ImageConfig
+		imageConfig = append(imageConfig, map[string]interface{}{
+			"entry_point":       aws.StringValueSlice(config.EntryPoint),
+			"command":           aws.StringValueSlice(config.Command),
+			"working_directory": aws.StringValue(config.WorkingDirectory),
+		})
+	}
+	if err := d.Set("image_config", imageConfig); err != nil {
+		return fmt.Errorf("Error setting image_config: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestExpandImageConfig(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Raw      map[string]interface{}
+		Expected *lambda.ImageConfig
+	}{
+		{
+			Name: "not set",
+			Raw:  map[string]interface{}{},
+		},
+		{
+			Name: "overrides",
+			Raw: map[string]interface{}{
+				"image_config": []interface{}{
+					map[string]interface{}{
+						"entry_point":       []interface{}{"/lambda-entrypoint.sh"},
+						"command":           []interface{}{"app.handler"},
+						"working_directory": "/var/task",
+					},
+				},
+			},
+			Expected: &lambda.ImageConfig{
+				EntryPoint:       aws.StringSlice([]string{"/lambda-entrypoint.sh"}),
+				Command:          aws.StringSlice([]string{"app.handler"}),
+				WorkingDirectory: aws.String("/var/task"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{
+				"image_config": imageConfigSchema(),
+			}, testCase.Raw)
+
+			got := expandImageConfig(d)
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Fatalf("expected %s, got %s", testCase.Expected, got)
+			}
+		})
+	}
+}
+
+// testUnknownValue is how the SDK marks values not known until apply
+const testUnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestCustomizeDiffPackageType(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Raw   map[string]interface{}
+		Error bool
+	}{
+		{
+			Name: "zip",
+			Raw:  map[string]interface{}{"handler": "main", "runtime": "go1.x", "filename": "main.zip"},
+		},
+		{
+			Name:  "zip without handler",
+			Raw:   map[string]interface{}{"runtime": "go1.x", "filename": "main.zip"},
+			Error: true,
+		},
+		{
+			Name:  "zip without deployment package",
+			Raw:   map[string]interface{}{"handler": "main", "runtime": "go1.x"},
+			Error: true,
+		},
+		{
+			Name: "zip with unknown deployment package",
+			Raw:  map[string]interface{}{"handler": "main", "runtime": "go1.x", "filename": testUnknownValue},
+		},
+		{
+			Name:  "zip with image_uri",
+			Raw:   map[string]interface{}{"handler": "main", "runtime": "go1.x", "filename": "main.zip", "image_uri": "repo:latest"},
+			Error: true,
+		},
+		{
+			Name: "image",
+			Raw:  map[string]interface{}{"package_type": lambda.PackageTypeImage, "image_uri": "repo:latest"},
+		},
+		{
+			Name:  "image with deployment package",
+			Raw:   map[string]interface{}{"package_type": lambda.PackageTypeImage, "image_uri": "repo:latest", "filename": "main.zip"},
+			Error: true,
+		},
+	}
+
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"package_type":      {Type: schema.TypeString, Optional: true, Default: lambda.PackageTypeZip},
+			"image_uri":         {Type: schema.TypeString, Optional: true},
+			"image_config":      imageConfigSchema(),
+			"handler":           {Type: schema.TypeString, Optional: true},
+			"runtime":           {Type: schema.TypeString, Optional: true},
+			"filename":          {Type: schema.TypeString, Optional: true},
+			"s3_bucket":         {Type: schema.TypeString, Optional: true},
+			"s3_key":            {Type: schema.TypeString, Optional: true},
+			"s3_object_version": {Type: schema.TypeString, Optional: true},
+			"source_dir":        {Type: schema.TypeString, Optional: true},
+			"build":             {Type: schema.TypeString, Optional: true},
+		},
+		CustomizeDiff: customizeDiffPackageType,
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := r.Diff(nil, terraform.NewResourceConfigRaw(testCase.Raw), nil)
+			if testCase.Error && err == nil {
+				t.Fatal("expected error")
+			}
+			if !testCase.Error && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
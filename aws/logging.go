@@ -0,0 +1,302 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// validLogRetentionDays are the retention periods accepted by CloudWatch
+// Logs, 0 never expires the events
+var validLogRetentionDays = []int{0, 1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1827, 3653}
+
+// logSubscriptionFilterName is the subscription filter managed on the log group
+const logSubscriptionFilterName = "serverless-logging"
+
+// loggingSchema is the log group of the function
+func loggingSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"retention_in_days": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntInSlice(validLogRetentionDays),
+				},
+				"kms_key_id": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"subscription_destination_arn": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"subscription_filter_pattern": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				"subscription_role_arn": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"delete_on_destroy": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"log_group_name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"log_group_arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// functionLogGroupName returns the log group Lambda writes the function logs to
+func functionLogGroupName(functionName string) string {
+	return "/aws/lambda/" + functionName
+}
+
+// createFunctionLogGroup creates the log group of the logging block before
+// the function, so that Lambda does not create it with infinite retention.
+// It returns whether the log group was created rather than adopted.
+func createFunctionLogGroup(conn *cloudwatchlogs.CloudWatchLogs, d *schema.ResourceData) (bool, error) {
+	if _, ok := d.GetOk("logging"); !ok {
+		return false, nil
+	}
+	name := functionLogGroupName(d.Get("function_name").(string))
+
+	log.Printf("[DEBUG] Creating log group %s", name)
+	_, err := conn.CreateLogGroup(&cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(name),
+	})
+	created := err == nil
+	if err != nil && !isAWSErr(err, cloudwatchlogs.ErrCodeResourceAlreadyExistsException, "") {
+		return false, fmt.Errorf("Error creating log group %s: %s", name, err)
+	}
+
+	return created, putFunctionLogGroup(conn, d, name)
+}
+
+// putFunctionLogGroup applies the retention, encryption and subscription of
+// the logging block to the log group
+func putFunctionLogGroup(conn *cloudwatchlogs.CloudWatchLogs, d *schema.ResourceData, name string) error {
+	logging := d.Get("logging").([]interface{})[0].(map[string]interface{})
+
+	if d.HasChange("logging.0.retention_in_days") || d.IsNewResource() {
+		if err := putLogGroupRetention(conn, name, logging["retention_in_days"].(int)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("logging.0.kms_key_id") {
+		if v := logging["kms_key_id"].(string); v != "" {
+			_, err := conn.AssociateKmsKey(&cloudwatchlogs.AssociateKmsKeyInput{
+				LogGroupName: aws.String(name),
+				KmsKeyId:     aws.String(v),
+			})
+			if err != nil {
+				return fmt.Errorf("Error associating KMS key %s to log group %s: %s", v, name, err)
+			}
+		} else {
+			_, err := conn.DisassociateKmsKey(&cloudwatchlogs.DisassociateKmsKeyInput{
+				LogGroupName: aws.String(name),
+			})
+			if err != nil {
+				return fmt.Errorf("Error disassociating KMS key from log group %s: %s", name, err)
+			}
+		}
+	}
+
+	if d.HasChange("logging.0.subscription_destination_arn") || d.HasChange("logging.0.subscription_filter_pattern") || d.HasChange("logging.0.subscription_role_arn") {
+		if err := putLogGroupSubscription(conn, name, logging); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putLogGroupRetention sets the retention of the log group, 0 never expires
+func putLogGroupRetention(conn *cloudwatchlogs.CloudWatchLogs, name string, days int) error {
+	if days == 0 {
+		_, err := conn.DeleteRetentionPolicy(&cloudwatchlogs.DeleteRetentionPolicyInput{
+			LogGroupName: aws.String(name),
+		})
+		if err != nil && !isAWSErr(err, cloudwatchlogs.ErrCodeResourceNotFoundException, "") {
+			return fmt.Errorf("Error removing retention of log group %s: %s", name, err)
+		}
+		return nil
+	}
+
+	_, err := conn.PutRetentionPolicy(&cloudwatchlogs.PutRetentionPolicyInput{
+		LogGroupName:    aws.String(name),
+		RetentionInDays: aws.Int64(int64(days)),
+	})
+	if err != nil {
+		return fmt.Errorf("Error setting retention of log group %s: %s", name, err)
+	}
+	return nil
+}
+
+// putLogGroupSubscription puts the subscription filter of the logging block,
+// or deletes it when no destination is set
+func putLogGroupSubscription(conn *cloudwatchlogs.CloudWatchLogs, name string, logging map[string]interface{}) error {
+	destination := logging["subscription_destination_arn"].(string)
+	if destination == "" {
+		_, err := conn.DeleteSubscriptionFilter(&cloudwatchlogs.DeleteSubscriptionFilterInput{
+			LogGroupName: aws.String(name),
+			FilterName:   aws.String(logSubscriptionFilterName),
+		})
+		if err != nil && !isAWSErr(err, cloudwatchlogs.ErrCodeResourceNotFoundException, "") {
+			return fmt.Errorf("Error deleting subscription filter of log group %s: %s", name, err)
+		}
+		return nil
+	}
+
+	input := &cloudwatchlogs.PutSubscriptionFilterInput{
+		LogGroupName:   aws.String(name),
+		FilterName:     aws.String(logSubscriptionFilterName),
+		FilterPattern:  aws.String(logging["subscription_filter_pattern"].(string)),
+		DestinationArn: aws.String(destination),
+	}
+	if v := logging["subscription_role_arn"].(string); v != "" {
+		input.RoleArn = aws.String(v)
+	}
+
+	log.Printf("[DEBUG] Putting subscription filter of log group %s: %s", name, input)
+	if _, err := conn.PutSubscriptionFilter(input); err != nil {
+		return fmt.Errorf("Error putting subscription filter of log group %s: %s", name, err)
+	}
+	return nil
+}
+
+// updateFunctionLogGroup applies the changes of the logging block, creating
+// the log group when the block is added
+func updateFunctionLogGroup(conn *cloudwatchlogs.CloudWatchLogs, d *schema.ResourceData) error {
+	if !d.HasChange("logging") {
+		return nil
+	}
+	name := functionLogGroupName(d.Id())
+
+	if _, ok := d.GetOk("logging"); !ok {
+		// The log group is left to Lambda, without our subscription
+		return putLogGroupSubscription(conn, name, map[string]interface{}{
+			"subscription_destination_arn": "",
+		})
+	}
+
+	_, err := conn.CreateLogGroup(&cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(name),
+	})
+	if err != nil && !isAWSErr(err, cloudwatchlogs.ErrCodeResourceAlreadyExistsException, "") {
+		return fmt.Errorf("Error creating log group %s: %s", name, err)
+	}
+	return putFunctionLogGroup(conn, d, name)
+}
+
+// readFunctionLogGroup refreshes the logging block
+func readFunctionLogGroup(conn *cloudwatchlogs.CloudWatchLogs, d *schema.ResourceData) error {
+	v, ok := d.GetOk("logging")
+	if !ok {
+		return nil
+	}
+	logging := v.([]interface{})[0].(map[string]interface{})
+	name := functionLogGroupName(d.Id())
+
+	var logGroup *cloudwatchlogs.LogGroup
+	err := conn.DescribeLogGroupsPages(&cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(name),
+	}, func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+		for _, group := range page.LogGroups {
+			if aws.StringValue(group.LogGroupName) == name {
+				logGroup = group
+				return false
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading log group %s: %s", name, err)
+	}
+	if logGroup == nil {
+		log.Printf("[WARN] Log group %s not found, removing from state", name)
+		return d.Set("logging", nil)
+	}
+
+	config := map[string]interface{}{
+		"retention_in_days":            int(aws.Int64Value(logGroup.RetentionInDays)),
+		"kms_key_id":                   aws.StringValue(logGroup.KmsKeyId),
+		"subscription_destination_arn": "",
+		"subscription_filter_pattern":  "",
+		"subscription_role_arn":        "",
+		"delete_on_destroy":            logging["delete_on_destroy"],
+		"log_group_name":               name,
+		"log_group_arn":                aws.StringValue(logGroup.Arn),
+	}
+
+	filters, err := conn.DescribeSubscriptionFilters(&cloudwatchlogs.DescribeSubscriptionFiltersInput{
+		LogGroupName:     aws.String(name),
+		FilterNamePrefix: aws.String(logSubscriptionFilterName),
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading subscription filters of log group %s: %s", name, err)
+	}
+	for _, filter := range filters.SubscriptionFilters {
+		if aws.StringValue(filter.FilterName) == logSubscriptionFilterName {
+			config["subscription_destination_arn"] = aws.StringValue(filter.DestinationArn)
+			config["subscription_filter_pattern"] = aws.StringValue(filter.FilterPattern)
+			config["subscription_role_arn"] = aws.StringValue(filter.RoleArn)
+		}
+	}
+
+	if err := d.Set("logging", []interface{}{config}); err != nil {
+		return fmt.Errorf("Error setting logging: %s", err)
+	}
+	return nil
+}
+
+// deleteFunctionLogGroup deletes the log group when delete_on_destroy is set
+func deleteFunctionLogGroup(conn *cloudwatchlogs.CloudWatchLogs, d *schema.ResourceData) error {
+	if !d.Get("logging.0.delete_on_destroy").(bool) {
+		return nil
+	}
+	name := functionLogGroupName(d.Get("function_name").(string))
+
+	log.Printf("[DEBUG] Deleting log group %s", name)
+	_, err := conn.DeleteLogGroup(&cloudwatchlogs.DeleteLogGroupInput{
+		LogGroupName: aws.String(name),
+	})
+	if err != nil && !isAWSErr(err, cloudwatchlogs.ErrCodeResourceNotFoundException, "") {
+		return fmt.Errorf("Error deleting log group %s: %s", name, err)
+	}
+	return nil
+}
+
+// rollbackFunctionLogGroup deletes the log group created for a function
+// that failed to be created
+func rollbackFunctionLogGroup(conn *cloudwatchlogs.CloudWatchLogs, d *schema.ResourceData, created bool) {
+	if !created || d.Id() != "" {
+		return
+	}
+	name := functionLogGroupName(d.Get("function_name").(string))
+	_, err := conn.DeleteLogGroup(&cloudwatchlogs.DeleteLogGroupInput{
+		LogGroupName: aws.String(name),
+	})
+	if err != nil {
+		log.Printf("[ERROR] Unable to delete log group %s: %s", name, err)
+	}
+}
@@ -0,0 +1,162 @@
+package aws
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// testCloudWatchLogsConn returns a client answering every call with err, or
+// an empty response, and the names of the log groups of the calls made
+func testCloudWatchLogsConn(t *testing.T, err error) (*cloudwatchlogs.CloudWatchLogs, *[]string) {
+	sess, serr := session.NewSession(&aws.Config{
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	if serr != nil {
+		t.Fatalf("unexpected error: %s", serr)
+	}
+
+	var calls []string
+	conn := cloudwatchlogs.New(sess)
+	conn.Handlers.Send.Clear()
+	conn.Handlers.Send.PushBack(func(r *request.Request) {
+		name := reflect.ValueOf(r.Params).Elem().FieldByName("LogGroupName").Interface().(*string)
+		calls = append(calls, r.Operation.Name+" "+aws.StringValue(name))
+		if err != nil {
+			r.Error = err
+			return
+		}
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		}
+	})
+	return conn, &calls
+}
+
+func TestFunctionLogGroupName(t *testing.T) {
+	if got := functionLogGroupName("TestFunction"); got != "/aws/lambda/TestFunction" {
+		t.Errorf("bad log group name: %s", got)
+	}
+}
+
+func TestLoggingRetentionInDays(t *testing.T) {
+	validate := loggingSchema().Elem.(*schema.Resource).Schema["retention_in_days"].ValidateFunc
+
+	testCases := []struct {
+		Value int
+		Error bool
+	}{
+		{Value: 0},
+		{Value: 14},
+		{Value: 3653},
+		{Value: -1, Error: true},
+		{Value: 2, Error: true},
+		{Value: 4000, Error: true},
+	}
+
+	for _, testCase := range testCases {
+		_, errors := validate(testCase.Value, "retention_in_days")
+		if testCase.Error && len(errors) == 0 {
+			t.Errorf("%d: expected error", testCase.Value)
+		}
+		if !testCase.Error && len(errors) > 0 {
+			t.Errorf("%d: unexpected errors: %v", testCase.Value, errors)
+		}
+	}
+}
+
+func TestPutLogGroupRetention(t *testing.T) {
+	testCases := []struct {
+		Days     int
+		Expected []string
+	}{
+		{Days: 0, Expected: []string{"DeleteRetentionPolicy /aws/lambda/TestFunction"}},
+		{Days: 14, Expected: []string{"PutRetentionPolicy /aws/lambda/TestFunction"}},
+	}
+
+	for _, testCase := range testCases {
+		conn, calls := testCloudWatchLogsConn(t, nil)
+		if err := putLogGroupRetention(conn, "/aws/lambda/TestFunction", testCase.Days); err != nil {
+			t.Fatalf("%d: unexpected error: %s", testCase.Days, err)
+		}
+		if !reflect.DeepEqual(*calls, testCase.Expected) {
+			t.Errorf("%d: expected %v, got %v", testCase.Days, testCase.Expected, *calls)
+		}
+	}
+}
+
+func TestDeleteFunctionLogGroup(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Logging  []interface{}
+		Err      error
+		Expected []string
+		Error    bool
+	}{
+		{
+			Name:     "no logging",
+			Expected: nil,
+		},
+		{
+			Name:     "kept",
+			Logging:  []interface{}{map[string]interface{}{"delete_on_destroy": false}},
+			Expected: nil,
+		},
+		{
+			Name:     "deleted",
+			Logging:  []interface{}{map[string]interface{}{"delete_on_destroy": true}},
+			Expected: []string{"DeleteLogGroup /aws/lambda/TestFunction"},
+		},
+		{
+			Name:     "already deleted",
+			Logging:  []interface{}{map[string]interface{}{"delete_on_destroy": true}},
+			Err:      awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "The specified log group does not exist.", nil),
+			Expected: []string{"DeleteLogGroup /aws/lambda/TestFunction"},
+		},
+		{
+			Name:     "error",
+			Logging:  []interface{}{map[string]interface{}{"delete_on_destroy": true}},
+			Err:      awserr.New(cloudwatchlogs.ErrCodeOperationAbortedException, "Aborted", nil),
+			Expected: []string{"DeleteLogGroup /aws/lambda/TestFunction"},
+			Error:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{
+				"function_name": {Type: schema.TypeString, Required: true},
+				"logging":       loggingSchema(),
+			}, map[string]interface{}{
+				"function_name": "TestFunction",
+				"logging":       testCase.Logging,
+			})
+
+			conn, calls := testCloudWatchLogsConn(t, testCase.Err)
+			err := deleteFunctionLogGroup(conn, d)
+			if testCase.Error && err == nil {
+				t.Fatal("expected error")
+			}
+			if !testCase.Error && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(*calls, testCase.Expected) {
+				t.Errorf("expected %v, got %v", testCase.Expected, *calls)
+			}
+		})
+	}
+}
@@ -1,8 +1,37 @@
 package aws
 
 import (
+	"github.com/alessandromr/go-aws-serverless/manager"
+	"github.com/alessandromr/go-aws-serverless/manager/create"
+	"github.com/alessandromr/go-aws-serverless/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/mutexkv"
 )
 
 // This is a global MutexKV for use within this plugin.
 var awsMutexKV = mutexkv.NewMutexKV()
+
+// awsMutexLambdaKey guards the global create and rollback lists of the
+// go-aws-serverless library
+const awsMutexLambdaKey = `aws_lambda_function`
+
+// withResourcesList runs f, which uses the resource lists of the library,
+// holding awsMutexLambdaKey. The lists are emptied before the lock is
+// released so that nothing leaks to the next caller.
+func withResourcesList(f func() error) error {
+	awsMutexKV.Lock(awsMutexLambdaKey)
+	defer awsMutexKV.Unlock(awsMutexLambdaKey)
+
+	//Remove all resources from singletons
+	defer manager.Clean()
+
+	return f()
+}
+
+// executeCreate creates the resources with the library, rolling back the
+// ones already created when any of them fails
+func executeCreate(resources ...resource.AWSResource) error {
+	return withResourcesList(func() error {
+		create.ResourcesList = append(create.ResourcesList, resources...)
+		return create.ExecuteCreate()
+	})
+}
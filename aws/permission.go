@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"log"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// functionPermission allows a service to invoke the function, created and
+// rolled back along with the function dependencies. Unlike the library
+// permission it can restrict the account owning the source.
+type functionPermission struct {
+	Action        string
+	FunctionName  string
+	Principal     string
+	SourceArn     string
+	SourceAccount string
+	StatementId   string
+}
+
+// Create adds the permission to the function policy
+func (p *functionPermission) Create() error {
+	auth.MakeClient(auth.Sess)
+	input := &lambda.AddPermissionInput{
+		Action:       aws.String(p.Action),
+		FunctionName: aws.String(p.FunctionName),
+		Principal:    aws.String(p.Principal),
+		SourceArn:    aws.String(p.SourceArn),
+		StatementId:  aws.String(p.StatementId),
+	}
+	if p.SourceAccount != "" {
+		input.SourceAccount = aws.String(p.SourceAccount)
+	}
+
+	log.Printf("[DEBUG] Adding permission %s to function %s", p.StatementId, p.FunctionName)
+	_, err := auth.Client.LambdaConn.AddPermission(input)
+	return err
+}
+
+// Delete removes the permission from the function policy
+func (p *functionPermission) Delete() error {
+	auth.MakeClient(auth.Sess)
+	_, err := auth.Client.LambdaConn.RemovePermission(&lambda.RemovePermissionInput{
+		FunctionName: aws.String(p.FunctionName),
+		StatementId:  aws.String(p.StatementId),
+	})
+	return err
+}
+
+// triggerDeleteFunctionInput deletes the function and the invoke permission
+// of its trigger, the other trigger resources are removed beforehand so that
+// their errors can be reported
+type triggerDeleteFunctionInput struct {
+	FunctionInput *lambda.DeleteFunctionInput
+	StatementId   string
+}
+
+// DeleteDependencies removes the invoke permission of the trigger
+func (input triggerDeleteFunctionInput) DeleteDependencies(lambdaResult *lambda.DeleteFunctionInput) {
+	auth.MakeClient(auth.Sess)
+	_, err := auth.Client.LambdaConn.RemovePermission(&lambda.RemovePermissionInput{
+		FunctionName: lambdaResult.FunctionName,
+		StatementId:  aws.String(input.StatementId),
+	})
+	if err != nil && !isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") {
+		log.Printf("[ERROR] Unable to remove permission %s of function %s: %s", input.StatementId, aws.StringValue(lambdaResult.FunctionName), err)
+	}
+}
+
+// GetFunctionInput returns the DeleteFunctionInput of the function
+func (input triggerDeleteFunctionInput) GetFunctionInput() *lambda.DeleteFunctionInput {
+	return input.FunctionInput
+}
@@ -0,0 +1,278 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/alessandromr/go-aws-serverless/services/function"
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func ResourceFunctionALB() *schema.Resource {
+	r := &schema.Resource{
+		Create: resourceFunctionALBCreate,
+		Read:   resourceFunctionALBRead,
+		Update: resourceFunctionALBUpdate,
+		Delete: resourceFunctionALBDelete,
+
+		Timeouts: functionTimeouts(),
+
+		CustomizeDiff: customdiff.All(
+			customizeDiffFunction(),
+			customizeDiffAlbConditions,
+			customizeDiffAlbTriggerDrift,
+		),
+
+		Schema: functionSchema(&schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"listener_arn": {
+					Type:     schema.TypeString,
+					Required: true,
+					ForceNew: true,
+				},
+				"priority": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntBetween(1, 50000),
+				},
+				"path_patterns": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"host_headers": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"http_header": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"http_header_name": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+							"values": {
+								Type:     schema.TypeList,
+								Required: true,
+								MinItems: 1,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+						},
+					},
+				},
+				"multi_value_headers": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"target_group_name": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Computed:     true,
+					ForceNew:     true,
+					ValidateFunc: validation.StringMatch(targetGroupNameRegexp, "must be 1 to 32 letters, numbers or hyphens, not beginning or ending with a hyphen"),
+				},
+				"target_group_arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"rule_arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"statement_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		}),
+	}
+
+	// trigger_drift is set when the target group or the listener rule was
+	// deleted outside of Terraform
+	r.Schema["trigger_drift"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Computed: true,
+	}
+	return r
+}
+
+func resourceFunctionALBCreate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	functionName := d.Get("function_name").(string)
+
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+	iamConn := auth.Client.IamConn
+
+	defer rollbackExecutionRole(iamConn, d)
+	if err := createExecutionRole(iamConn, d, nil); err != nil {
+		return err
+	}
+
+	logsConn := cloudwatchlogs.New(auth.Sess)
+	logGroupCreated, err := createFunctionLogGroup(logsConn, d)
+	defer rollbackFunctionLogGroup(logsConn, d, logGroupCreated)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, d.Get("role").(string))
+
+	functionCode, release, err := expandFunctionCode(d, m.(*Config))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	funcParam, err := expandCreateFunctionInput(d, functionCode)
+	if err != nil {
+		return err
+	}
+
+	input := albCreateFunctionInput{
+		FunctionInput: funcParam,
+		Data:          d,
+	}
+
+	response, err := createFunctionWithRetry(conn, d, input, release)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(functionName)
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	event["target_group_name"] = response["TargetGroupName"]
+	event["target_group_arn"] = response["TargetGroupArn"]
+	event["rule_arn"] = response["RuleArn"]
+	if err := d.Set("event", []interface{}{event}); err != nil {
+		return fmt.Errorf("Error setting event: %s", err)
+	}
+
+	return resourceFunctionALBRead(d, m)
+}
+
+func resourceFunctionALBRead(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+
+	if err := readFunctionConfiguration(auth.Client.LambdaConn, d); err != nil {
+		if isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") && !d.IsNewResource() {
+			log.Printf("[WARN] Lambda function %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	if err := readFunctionVersions(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionConcurrency(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionAsyncConfig(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := readAlbTrigger(elbv2.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourceFunctionALBUpdate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+
+	if err := updateExecutionRole(auth.Client.IamConn, d, nil); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConfiguration(conn, d); err != nil {
+		return err
+	}
+
+	if err := deleteReplacedExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionTags(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionCode(conn, d, m.(*Config)); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAlias(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConcurrency(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAsyncConfig(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := updateAlbTrigger(elbv2.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return resourceFunctionALBRead(d, m)
+}
+
+func resourceFunctionALBDelete(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	log.Printf("[INFO] Deleting Serverless Function: %s", d.Id())
+	auth.MakeClient(auth.Sess)
+
+	if err := deleteAlbTrigger(elbv2.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	input := triggerDeleteFunctionInput{
+		FunctionInput: &lambda.DeleteFunctionInput{
+			FunctionName: aws.String(d.Get("function_name").(string)),
+		},
+		StatementId: albStatementId(d.Get("event.0.target_group_name").(string)),
+	}
+
+	function.DeleteFunction(input)
+
+	if err := deleteExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := deleteFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
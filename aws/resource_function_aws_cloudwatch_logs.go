@@ -0,0 +1,221 @@
+package aws
+
+import (
+	"log"
+
+	"github.com/alessandromr/go-aws-serverless/services/function"
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func ResourceFunctionCloudWatchLogs() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceFunctionCloudWatchLogsCreate,
+		Read:   resourceFunctionCloudWatchLogsRead,
+		Update: resourceFunctionCloudWatchLogsUpdate,
+		Delete: resourceFunctionCloudWatchLogsDelete,
+
+		Timeouts: functionTimeouts(),
+
+		CustomizeDiff: customdiff.All(
+			customizeDiffFunction(),
+			customizeDiffCloudWatchLogsTrigger,
+		),
+
+		Schema: functionSchema(&schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"log_group_name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"filter_pattern": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "",
+				},
+				"filter_name": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"log_group_arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"statement_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		}),
+	}
+}
+
+func resourceFunctionCloudWatchLogsCreate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	functionName := d.Get("function_name").(string)
+
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+	iamConn := auth.Client.IamConn
+
+	defer rollbackExecutionRole(iamConn, d)
+	if err := createExecutionRole(iamConn, d, nil); err != nil {
+		return err
+	}
+
+	logsConn := cloudwatchlogs.New(auth.Sess)
+	logGroupCreated, err := createFunctionLogGroup(logsConn, d)
+	defer rollbackFunctionLogGroup(logsConn, d, logGroupCreated)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, d.Get("role").(string))
+
+	functionCode, release, err := expandFunctionCode(d, m.(*Config))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	funcParam, err := expandCreateFunctionInput(d, functionCode)
+	if err != nil {
+		return err
+	}
+
+	input := cloudWatchLogsCreateFunctionInput{
+		FunctionInput: funcParam,
+		Event:         d.Get("event").([]interface{})[0].(map[string]interface{}),
+	}
+
+	if _, err := createFunctionWithRetry(conn, d, input, release); err != nil {
+		return err
+	}
+
+	d.SetId(functionName)
+
+	return resourceFunctionCloudWatchLogsRead(d, m)
+}
+
+func resourceFunctionCloudWatchLogsRead(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+
+	if err := readFunctionConfiguration(auth.Client.LambdaConn, d); err != nil {
+		if isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") && !d.IsNewResource() {
+			log.Printf("[WARN] Lambda function %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	if err := readFunctionVersions(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionConcurrency(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionAsyncConfig(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := readCloudWatchLogsTrigger(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourceFunctionCloudWatchLogsUpdate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+
+	if err := updateExecutionRole(auth.Client.IamConn, d, nil); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConfiguration(conn, d); err != nil {
+		return err
+	}
+
+	if err := deleteReplacedExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionTags(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionCode(conn, d, m.(*Config)); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAlias(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConcurrency(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAsyncConfig(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := updateCloudWatchLogsTrigger(cloudwatchlogs.New(auth.Sess), conn, d); err != nil {
+		return err
+	}
+
+	return resourceFunctionCloudWatchLogsRead(d, m)
+}
+
+func resourceFunctionCloudWatchLogsDelete(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	log.Printf("[INFO] Deleting Serverless Function: %s", d.Id())
+	auth.MakeClient(auth.Sess)
+
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	subscription := expandLogsSubscription(event, d.Get("function_name").(string), cloudWatchLogsTriggerTarget(d))
+	if subscription.LogGroupName != "" {
+		if err := deleteLogsSubscription(cloudwatchlogs.New(auth.Sess), subscription); err != nil {
+			return err
+		}
+	}
+
+	input := triggerDeleteFunctionInput{
+		FunctionInput: &lambda.DeleteFunctionInput{
+			FunctionName: aws.String(d.Get("function_name").(string)),
+		},
+		StatementId: cloudWatchLogsStatementId(subscription.LogGroupName),
+	}
+
+	function.DeleteFunction(input)
+
+	if err := deleteExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := deleteFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"log"
+
+	"github.com/alessandromr/go-aws-serverless/services/function"
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func ResourceFunctionCognitoTrigger() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceFunctionCognitoTriggerCreate,
+		Read:   resourceFunctionCognitoTriggerRead,
+		Update: resourceFunctionCognitoTriggerUpdate,
+		Delete: resourceFunctionCognitoTriggerDelete,
+
+		Timeouts: functionTimeouts(),
+
+		CustomizeDiff: customizeDiffFunction(),
+
+		Schema: functionSchema(&schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"user_pool_id": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"trigger_type": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice(cognitoTriggerTypes(), false),
+				},
+				"user_pool_arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"statement_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		}),
+	}
+}
+
+func resourceFunctionCognitoTriggerCreate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	functionName := d.Get("function_name").(string)
+
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+	iamConn := auth.Client.IamConn
+
+	defer rollbackExecutionRole(iamConn, d)
+	if err := createExecutionRole(iamConn, d, nil); err != nil {
+		return err
+	}
+
+	logsConn := cloudwatchlogs.New(auth.Sess)
+	logGroupCreated, err := createFunctionLogGroup(logsConn, d)
+	defer rollbackFunctionLogGroup(logsConn, d, logGroupCreated)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, d.Get("role").(string))
+
+	functionCode, release, err := expandFunctionCode(d, m.(*Config))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	funcParam, err := expandCreateFunctionInput(d, functionCode)
+	if err != nil {
+		return err
+	}
+
+	input := cognitoCreateFunctionInput{
+		FunctionInput: funcParam,
+		Event:         d.Get("event").([]interface{})[0].(map[string]interface{}),
+	}
+
+	if _, err := createFunctionWithRetry(conn, d, input, release); err != nil {
+		return err
+	}
+
+	d.SetId(functionName)
+
+	return resourceFunctionCognitoTriggerRead(d, m)
+}
+
+func resourceFunctionCognitoTriggerRead(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+
+	if err := readFunctionConfiguration(auth.Client.LambdaConn, d); err != nil {
+		if isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") && !d.IsNewResource() {
+			log.Printf("[WARN] Lambda function %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	if err := readFunctionVersions(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionConcurrency(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionAsyncConfig(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := readCognitoTrigger(cognitoidentityprovider.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourceFunctionCognitoTriggerUpdate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+
+	if err := updateExecutionRole(auth.Client.IamConn, d, nil); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConfiguration(conn, d); err != nil {
+		return err
+	}
+
+	if err := deleteReplacedExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionTags(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionCode(conn, d, m.(*Config)); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAlias(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConcurrency(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAsyncConfig(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := updateCognitoTrigger(cognitoidentityprovider.New(auth.Sess), conn, d); err != nil {
+		return err
+	}
+
+	return resourceFunctionCognitoTriggerRead(d, m)
+}
+
+func resourceFunctionCognitoTriggerDelete(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	log.Printf("[INFO] Deleting Serverless Function: %s", d.Id())
+	auth.MakeClient(auth.Sess)
+
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	trigger := expandCognitoTrigger(event, cognitoTriggerTarget(d))
+	if trigger.TriggerType != "" {
+		if err := putCognitoTrigger(cognitoidentityprovider.New(auth.Sess), trigger, false); err != nil {
+			return err
+		}
+	}
+
+	input := triggerDeleteFunctionInput{
+		FunctionInput: &lambda.DeleteFunctionInput{
+			FunctionName: aws.String(d.Get("function_name").(string)),
+		},
+		StatementId: cognitoStatementId(trigger.UserPoolId),
+	}
+
+	function.DeleteFunction(input)
+
+	if err := deleteExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := deleteFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
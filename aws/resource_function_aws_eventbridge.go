@@ -0,0 +1,260 @@
+package aws
+
+import (
+	"log"
+
+	"github.com/alessandromr/go-aws-serverless/services/function"
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func ResourceFunctionEventBridge() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceFunctionEventBridgeCreate,
+		Read:   resourceFunctionEventBridgeRead,
+		Update: resourceFunctionEventBridgeUpdate,
+		Delete: resourceFunctionEventBridgeDelete,
+
+		Timeouts: functionTimeouts(),
+
+		CustomizeDiff: customizeDiffFunction(),
+
+		Schema: functionSchema(&schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"event_pattern": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validateEventPattern,
+					StateFunc:    eventPatternStateFunc,
+				},
+				"event_bus_name": {
+					Type:     schema.TypeString,
+					Optional: true,
+					ForceNew: true,
+					Default:  eventBridgeDefaultBus,
+				},
+				"rule_name": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Computed: true,
+					ForceNew: true,
+				},
+				"description": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"input_transformer": {
+					Type:     schema.TypeList,
+					Optional: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"input_paths": {
+								Type:     schema.TypeMap,
+								Optional: true,
+								Elem:     &schema.Schema{Type: schema.TypeString},
+							},
+							"input_template": {
+								Type:     schema.TypeString,
+								Required: true,
+							},
+						},
+					},
+				},
+				"maximum_retry_attempts": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      185,
+					ValidateFunc: validation.IntBetween(0, 185),
+				},
+				"maximum_event_age_in_seconds": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      86400,
+					ValidateFunc: validation.IntBetween(60, 86400),
+				},
+				"dead_letter_arn": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateSqsQueueArn,
+				},
+				"rule_arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"statement_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		}),
+	}
+}
+
+func resourceFunctionEventBridgeCreate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	functionName := d.Get("function_name").(string)
+
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+	iamConn := auth.Client.IamConn
+
+	defer rollbackExecutionRole(iamConn, d)
+	if err := createExecutionRole(iamConn, d, nil); err != nil {
+		return err
+	}
+
+	logsConn := cloudwatchlogs.New(auth.Sess)
+	logGroupCreated, err := createFunctionLogGroup(logsConn, d)
+	defer rollbackFunctionLogGroup(logsConn, d, logGroupCreated)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, d.Get("role").(string))
+
+	functionCode, release, err := expandFunctionCode(d, m.(*Config))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	funcParam, err := expandCreateFunctionInput(d, functionCode)
+	if err != nil {
+		return err
+	}
+
+	input := eventBridgeCreateFunctionInput{
+		FunctionInput: funcParam,
+		Data:          d,
+	}
+
+	if _, err := createFunctionWithRetry(conn, d, input, release); err != nil {
+		return err
+	}
+
+	d.SetId(functionName)
+
+	return resourceFunctionEventBridgeRead(d, m)
+}
+
+func resourceFunctionEventBridgeRead(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+
+	if err := readFunctionConfiguration(auth.Client.LambdaConn, d); err != nil {
+		if isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") && !d.IsNewResource() {
+			log.Printf("[WARN] Lambda function %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	if err := readFunctionVersions(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionConcurrency(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionAsyncConfig(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := readEventBridgeTrigger(eventbridge.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resourceFunctionEventBridgeUpdate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+
+	if err := updateExecutionRole(auth.Client.IamConn, d, nil); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConfiguration(conn, d); err != nil {
+		return err
+	}
+
+	if err := deleteReplacedExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionTags(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionCode(conn, d, m.(*Config)); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAlias(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConcurrency(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAsyncConfig(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := updateEventBridgeTrigger(eventbridge.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return resourceFunctionEventBridgeRead(d, m)
+}
+
+func resourceFunctionEventBridgeDelete(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	log.Printf("[INFO] Deleting Serverless Function: %s", d.Id())
+	auth.MakeClient(auth.Sess)
+
+	trigger := expandEventBridgeTrigger(d, eventBridgeTriggerTarget(d))
+	if err := deleteEventBridgeTrigger(eventbridge.New(auth.Sess), trigger); err != nil {
+		return err
+	}
+
+	input := triggerDeleteFunctionInput{
+		FunctionInput: &lambda.DeleteFunctionInput{
+			FunctionName: aws.String(d.Get("function_name").(string)),
+		},
+		StatementId: eventBridgeStatementId(trigger.BusName, trigger.RuleName),
+	}
+
+	function.DeleteFunction(input)
+
+	if err := deleteExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := deleteFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	return nil
+}
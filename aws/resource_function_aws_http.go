@@ -1,20 +1,16 @@
 package aws
 
 import (
-	"fmt"
-	"time"
-
 	"github.com/aws/aws-sdk-go/aws"
 
-	"errors"
 	"log"
 
 	"github.com/alessandromr/go-aws-serverless/services/function"
 	"github.com/alessandromr/go-aws-serverless/utils/auth"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/lambda"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
@@ -35,173 +31,56 @@ func ResourceFunctionHTTP() *schema.Resource {
 		Update: resourceFunctionHTTPUpdate,
 		Delete: resourceFunctionHTTPDelete,
 
-		Schema: map[string]*schema.Schema{
-			"filename": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"s3_bucket", "s3_key", "s3_object_version"},
-			},
-			"s3_bucket": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"filename"},
-			},
-			"s3_key": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"filename"},
-			},
-			"s3_object_version": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"filename"},
-			},
-			"description": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"memory_size": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  128,
-			},
-			"runtime": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ValidateFunc: validation.StringInSlice(validLambdaRuntimes, false),
-			},
-			"environment": {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"variables": {
-							Type:     schema.TypeMap,
-							Optional: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-						},
-					},
+		Timeouts: functionTimeouts(),
+
+		CustomizeDiff: customizeDiffFunction(),
+
+		Schema: functionSchema(&schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"path": {
+					Type:     schema.TypeString,
+					Required: true,
 				},
-			},
-			"timeout": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  3,
-			},
-			"vpc_config": {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"subnet_ids": {
-							Type:     schema.TypeSet,
-							Required: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-							Set:      schema.HashString,
-						},
-						"security_group_ids": {
-							Type:     schema.TypeSet,
-							Required: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-							Set:      schema.HashString,
-						},
-						"vpc_id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-					},
+				"http_method": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice(validHTTPMethod, false),
 				},
-			},
-			"function_name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"handler": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"arn": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"last_modified": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"source_code_hash": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Computed: true,
-			},
-			"source_code_size": {
-				Type:     schema.TypeInt,
-				Computed: true,
-			},
-			"publish": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-			},
-			"role": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"event": {
-				Type:     schema.TypeList,
-				Required: true,
-				MinItems: 1,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"path": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"http_method": {
-							Type:         schema.TypeString,
-							Required:     true,
-							ValidateFunc: validation.StringInSlice(validHTTPMethod, false),
-						},
-						"http_integration_method": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"already_existing": {
-							Type:     schema.TypeBool,
-							Optional: true,
-							Default:  false,
-						},
-						"api_id": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						"api_name": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						"arn": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"root_resource_id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"resource_id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"created_date": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-					},
+				"http_integration_method": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"already_existing": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"api_id": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"api_name": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"arn": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"root_resource_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"resource_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"created_date": {
+					Type:     schema.TypeString,
+					Computed: true,
 				},
 			},
-		},
+		}),
 	}
 }
 
@@ -210,120 +89,40 @@ func resourceFunctionHTTPCreate(d *schema.ResourceData, m interface{}) error {
 	var err error
 
 	functionName := d.Get("function_name").(string)
-	iamRole := d.Get("role").(string)
-	// reservedConcurrentExecutions := d.Get("reserved_concurrent_executions").(int)
-	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, iamRole)
 
-	filename, hasFilename := d.GetOk("filename")
-	s3Bucket, bucketOk := d.GetOk("s3_bucket")
-	s3Key, keyOk := d.GetOk("s3_key")
-	s3ObjectVersion, versionOk := d.GetOk("s3_object_version")
-
-	if !hasFilename && !bucketOk && !keyOk && !versionOk {
-		return errors.New("filename or s3_* attributes must be set")
-	}
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+	iamConn := auth.Client.IamConn
 
-	var functionCode *lambda.FunctionCode
-	if hasFilename {
-		// Grab an exclusive lock so that we're only reading one function into
-		// memory at a time.
-		// See https://github.com/hashicorp/terraform/issues/9364
-		awsMutexKV.Lock(awsMutexLambdaKey)
-		defer awsMutexKV.Unlock(awsMutexLambdaKey)
-		file, err := loadFileContent(filename.(string))
-		if err != nil {
-			return fmt.Errorf("Unable to load %q: %s", filename.(string), err)
-		}
-		functionCode = &lambda.FunctionCode{
-			ZipFile: file,
-		}
-	} else {
-		if !bucketOk || !keyOk {
-			return errors.New("s3_bucket and s3_key must all be set while using S3 code source")
-		}
-		functionCode = &lambda.FunctionCode{
-			S3Bucket: aws.String(s3Bucket.(string)),
-			S3Key:    aws.String(s3Key.(string)),
-		}
-		if versionOk {
-			functionCode.S3ObjectVersion = aws.String(s3ObjectVersion.(string))
-		}
-	}
-
-	funcParam := &lambda.CreateFunctionInput{
-		Code:         functionCode,
-		Description:  aws.String(d.Get("description").(string)),
-		FunctionName: aws.String(functionName),
-		Handler:      aws.String(d.Get("handler").(string)),
-		MemorySize:   aws.Int64(int64(d.Get("memory_size").(int))),
-		Role:         aws.String(iamRole),
-		Runtime:      aws.String(d.Get("runtime").(string)),
-		Timeout:      aws.Int64(int64(d.Get("timeout").(int))),
-		Publish:      aws.Bool(d.Get("publish").(bool)),
-	}
-
-	if v, ok := d.GetOk("layers"); ok && len(v.([]interface{})) > 0 {
-		funcParam.Layers = expandStringList(v.([]interface{}))
-	}
-
-	if v, ok := d.GetOk("dead_letter_config"); ok {
-		dlcMaps := v.([]interface{})
-		if len(dlcMaps) == 1 { // Schema guarantees either 0 or 1
-			// Prevent panic on nil dead_letter_config. See GH-14961
-			if dlcMaps[0] == nil {
-				return fmt.Errorf("Nil dead_letter_config supplied for function: %s", functionName)
-			}
-			dlcMap := dlcMaps[0].(map[string]interface{})
-			funcParam.DeadLetterConfig = &lambda.DeadLetterConfig{
-				TargetArn: aws.String(dlcMap["target_arn"].(string)),
-			}
-		}
-	}
-
-	if v, ok := d.GetOk("vpc_config"); ok && len(v.([]interface{})) > 0 {
-		config := v.([]interface{})[0].(map[string]interface{})
-
-		funcParam.VpcConfig = &lambda.VpcConfig{
-			SecurityGroupIds: expandStringSet(config["security_group_ids"].(*schema.Set)),
-			SubnetIds:        expandStringSet(config["subnet_ids"].(*schema.Set)),
-		}
+	defer rollbackExecutionRole(iamConn, d)
+	if err := createExecutionRole(iamConn, d, nil); err != nil {
+		return err
 	}
 
-	if v, ok := d.GetOk("tracing_config"); ok {
-		tracingConfig := v.([]interface{})
-		tracing := tracingConfig[0].(map[string]interface{})
-		funcParam.TracingConfig = &lambda.TracingConfig{
-			Mode: aws.String(tracing["mode"].(string)),
-		}
+	logsConn := cloudwatchlogs.New(auth.Sess)
+	logGroupCreated, err := createFunctionLogGroup(logsConn, d)
+	defer rollbackFunctionLogGroup(logsConn, d, logGroupCreated)
+	if err != nil {
+		return err
 	}
 
-	if v, ok := d.GetOk("environment"); ok {
-		environments := v.([]interface{})
-		environment, ok := environments[0].(map[string]interface{})
-		if !ok {
-			return errors.New("At least one field is expected inside environment")
-		}
-
-		if environmentVariables, ok := environment["variables"]; ok {
-			variables := readEnvironmentVariables(environmentVariables.(map[string]interface{}))
-
-			funcParam.Environment = &lambda.Environment{
-				Variables: aws.StringMap(variables),
-			}
-		}
-	}
+	iamRole := d.Get("role").(string)
+	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, iamRole)
 
-	if v, ok := d.GetOk("kms_key_arn"); ok {
-		funcParam.KMSKeyArn = aws.String(v.(string))
+	functionCode, release, err := expandFunctionCode(d, m.(*Config))
+	if err != nil {
+		return err
 	}
+	defer release()
 
-	if v, exists := d.GetOk("tags"); exists {
-		funcParam.Tags = tagsFromMapGeneric(v.(map[string]interface{}))
+	funcParam, err := expandCreateFunctionInput(d, functionCode)
+	if err != nil {
+		return err
 	}
 
 	event := d.Get("event").([]interface{})[0].(map[string]interface{})
 
-	input := function.HTTPCreateFunctionInput{
+	input := httpCreateFunctionInput{function.HTTPCreateFunctionInput{
 		FunctionInput: funcParam,
 		HTTPCreateEvent: function.HTTPCreateEvent{
 			Path:     aws.String(event["path"].(string)),
@@ -332,61 +131,10 @@ func resourceFunctionHTTPCreate(d *schema.ResourceData, m interface{}) error {
 			ApiId:    aws.String(event["api_id"].(string)),
 			ApiName:  aws.String(event["api_name"].(string)),
 		},
-	}
-	var response map[string]interface{}
-
-	err = resource.Retry(1*time.Minute, func() *resource.RetryError {
-		var intErr error
-		response, intErr = function.CreateFunction(input)
-
-		if intErr != nil {
-			log.Printf("[DEBUG] Error creating Lambda Function: %s", err)
-
-			if isAWSErr(intErr, "InvalidParameterValueException", "The role defined for the function cannot be assumed by Lambda") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
-				return resource.RetryableError(intErr)
-			}
-			if isAWSErr(intErr, "InvalidParameterValueException", "The provided execution role does not have permissions") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
-				return resource.RetryableError(intErr)
-			}
-			if isAWSErr(intErr, "InvalidParameterValueException", "Your request has been throttled by EC2") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
-				return resource.RetryableError(intErr)
-			}
-			if isAWSErr(intErr, "InvalidParameterValueException", "Lambda was unable to configure access to your environment variables because the KMS key is invalid for CreateGrant") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", intErr)
-				return resource.RetryableError(intErr)
-			}
-			return resource.NonRetryableError(intErr)
-		}
-		return nil
-	})
-
+	}}
+	response, err := createFunctionWithRetry(conn, d, input, release)
 	if err != nil {
-		if !isResourceTimeoutError(err) && !isAWSErr(err, "InvalidParameterValueException", "Your request has been throttled by EC2") {
-			return fmt.Errorf("Error creating Lambda function: %s", err)
-		}
-		// Allow additional time for slower uploads or EC2 throttling
-		err := resource.Retry(d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
-			response, err = function.CreateFunction(input)
-			if err != nil {
-				log.Printf("[DEBUG] Error creating Lambda Function: %s", err)
-
-				if isAWSErr(err, "InvalidParameterValueException", "Your request has been throttled by EC2") {
-					log.Printf("[DEBUG] Received %s, retrying CreateFunction", err)
-					return resource.RetryableError(err)
-				}
-				return resource.NonRetryableError(err)
-			}
-			return nil
-		})
-		if isResourceTimeoutError(err) {
-			response, err = function.CreateFunction(input)
-		}
-		if err != nil {
-			return fmt.Errorf("Error creating Lambda function: %s", err)
-		}
+		return err
 	}
 
 	d.SetId(d.Get("function_name").(string))
@@ -435,17 +183,6 @@ func resourceFunctionHTTPRead(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	d.Set("function_name", functionOutput["FunctionName"])
-	d.Set("role", functionOutput["Role"])
-	d.Set("memory_size", functionOutput["MemorySize"])
-	d.Set("runtime", functionOutput["Runtime"])
-	d.Set("handler", functionOutput["Handler"])
-	d.Set("description", functionOutput["Description"])
-	d.Set("last_modified", functionOutput["LastModified"])
-	d.Set("timeout", functionOutput["Timeout"])
-	d.Set("source_code_hash", functionOutput["CodeSha256"])
-	d.Set("source_code_size", functionOutput["CodeSize"])
-
 	log.Println(*functionOutput["RestApi"].(apigateway.RestApi).Id)
 	log.Println(d.Get("event"))
 
@@ -460,10 +197,70 @@ func resourceFunctionHTTPRead(d *schema.ResourceData, m interface{}) error {
 		},
 	})
 
+	if err := readFunctionConfiguration(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionVersions(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionConcurrency(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionAsyncConfig(auth.Client.LambdaConn, d); err != nil {
+		return err
+	}
+
+	if err := readFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func resourceFunctionHTTPUpdate(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+
+	if err := updateExecutionRole(auth.Client.IamConn, d, nil); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConfiguration(conn, d); err != nil {
+		return err
+	}
+
+	if err := deleteReplacedExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionTags(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionCode(conn, d, m.(*Config)); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAlias(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionConcurrency(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionAsyncConfig(conn, d); err != nil {
+		return err
+	}
+
+	if err := updateFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
 	return resourceFunctionHTTPRead(d, m)
 }
 
@@ -485,5 +282,32 @@ func resourceFunctionHTTPDelete(d *schema.ResourceData, m interface{}) error {
 	}
 
 	function.DeleteFunction(input)
+
+	auth.MakeClient(auth.Sess)
+	if err := deleteExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := deleteFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
 	return nil
 }
+
+// httpCreateFunctionInput creates the API Gateway dependencies with the
+// library, which fills its resource lists while creating them
+type httpCreateFunctionInput struct {
+	function.HTTPCreateFunctionInput
+}
+
+// CreateDependencies creates the API Gateway method integrated with the
+// function
+func (input httpCreateFunctionInput) CreateDependencies(lambdaResult *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := withResourcesList(func() error {
+		var err error
+		out, err = input.HTTPCreateFunctionInput.CreateDependencies(lambdaResult)
+		return err
+	})
+	return out, err
+}
@@ -2,23 +2,19 @@ package aws
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 
-	"errors"
 	"github.com/alessandromr/go-aws-serverless/services/function"
 	"github.com/alessandromr/go-aws-serverless/utils/auth"
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/lambda"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"log"
 )
 
-const awsMutexLambdaKey = `aws_lambda_function`
-
 var validS3Events = []string{
 	"s3:ObjectCreated:*",
 	"s3:ObjectCreated:Put",
@@ -44,168 +40,68 @@ func ResourceFunctionS3() *schema.Resource {
 		Update: resourceFunctionS3Update,
 		Delete: resourceFunctionS3Delete,
 
-		Schema: map[string]*schema.Schema{
-			"filename": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"s3_bucket", "s3_key", "s3_object_version"},
-			},
-			"s3_bucket": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"filename"},
-			},
-			"s3_key": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"filename"},
-			},
-			"s3_object_version": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"filename"},
-			},
-			"description": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"memory_size": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  128,
-			},
-			"runtime": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ValidateFunc: validation.StringInSlice(validLambdaRuntimes, false),
-			},
-			"environment": {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"variables": {
-							Type:     schema.TypeMap,
-							Optional: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-						},
-					},
+		Timeouts: functionTimeouts(),
+
+		CustomizeDiff: customdiff.All(
+			customizeDiffFunction(),
+			customizeDiffS3BucketRegion,
+			customizeDiffS3Notification,
+		),
+
+		Schema: functionSchema(&schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"bucket": {
+					Type:     schema.TypeString,
+					Required: true,
+					ForceNew: true,
 				},
-			},
-			"timeout": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Default:  3,
-			},
-			"vpc_config": {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"subnet_ids": {
-							Type:     schema.TypeSet,
-							Required: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-							Set:      schema.HashString,
-						},
-						"security_group_ids": {
-							Type:     schema.TypeSet,
-							Required: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-							Set:      schema.HashString,
-						},
-						"vpc_id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
+				"event_types": {
+					Type:     schema.TypeSet,
+					Required: true,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validation.StringInSlice(validS3Events, false),
 					},
+					Set: schema.HashString,
 				},
-			},
-			"function_name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"handler": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"arn": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"last_modified": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"source_code_hash": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Computed: true,
-			},
-			"source_code_size": {
-				Type:     schema.TypeInt,
-				Computed: true,
-			},
-			"publish": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-			},
-			"role": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"event": {
-				Type:     schema.TypeList,
-				Required: true,
-				MinItems: 1,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"bucket": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"event_types": {
-							Type:     schema.TypeSet,
-							Required: true,
-							Elem: &schema.Schema{
-								Type:         schema.TypeString,
-								ValidateFunc: validation.StringInSlice(validS3Events, false),
-							},
-							Set: schema.HashString,
-						},
-						"event_key": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						"object_prefix": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						"object_suffix": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						"bucket_domain_name": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"bucket_regional_domain_name": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"statement_id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-					},
+				"event_key": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					ConflictsWith: []string{"event.0.object_prefix", "event.0.object_suffix"},
+				},
+				"object_prefix": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"object_suffix": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"bucket_owner": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ForceNew:     true,
+					ValidateFunc: validation.StringMatch(accountIdRegexp, "must be a 12 digit AWS account id"),
+				},
+				"allow_cross_region": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"bucket_domain_name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"bucket_regional_domain_name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"statement_id": {
+					Type:     schema.TypeString,
+					Computed: true,
 				},
 			},
-		},
+		}),
 	}
 }
 
@@ -213,251 +109,143 @@ func resourceFunctionS3Create(d *schema.ResourceData, m interface{}) error {
 	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
 
 	functionName := d.Get("function_name").(string)
-	iamRole := d.Get("role").(string)
-	// reservedConcurrentExecutions := d.Get("reserved_concurrent_executions").(int)
-	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, iamRole)
 
-	filename, hasFilename := d.GetOk("filename")
-	s3Bucket, bucketOk := d.GetOk("s3_bucket")
-	s3Key, keyOk := d.GetOk("s3_key")
-	s3ObjectVersion, versionOk := d.GetOk("s3_object_version")
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
+	iamConn := auth.Client.IamConn
 
-	if !hasFilename && !bucketOk && !keyOk && !versionOk {
-		return errors.New("filename or s3_* attributes must be set")
+	defer rollbackExecutionRole(iamConn, d)
+	if err := createExecutionRole(iamConn, d, s3ExecutionRoleStatements(d)); err != nil {
+		return err
 	}
 
-	var functionCode *lambda.FunctionCode
-	if hasFilename {
-		// Grab an exclusive lock so that we're only reading one function into
-		// memory at a time.
-		// See https://github.com/hashicorp/terraform/issues/9364
-		awsMutexKV.Lock(awsMutexLambdaKey)
-		defer awsMutexKV.Unlock(awsMutexLambdaKey)
-		file, err := loadFileContent(filename.(string))
-		if err != nil {
-			return fmt.Errorf("Unable to load %q: %s", filename.(string), err)
-		}
-		functionCode = &lambda.FunctionCode{
-			ZipFile: file,
-		}
-	} else {
-		if !bucketOk || !keyOk {
-			return errors.New("s3_bucket and s3_key must all be set while using S3 code source")
-		}
-		functionCode = &lambda.FunctionCode{
-			S3Bucket: aws.String(s3Bucket.(string)),
-			S3Key:    aws.String(s3Key.(string)),
-		}
-		if versionOk {
-			functionCode.S3ObjectVersion = aws.String(s3ObjectVersion.(string))
-		}
+	logsConn := cloudwatchlogs.New(auth.Sess)
+	logGroupCreated, err := createFunctionLogGroup(logsConn, d)
+	defer rollbackFunctionLogGroup(logsConn, d, logGroupCreated)
+	if err != nil {
+		return err
 	}
 
-	funcParam := &lambda.CreateFunctionInput{
-		Code:         functionCode,
-		Description:  aws.String(d.Get("description").(string)),
-		FunctionName: aws.String(functionName),
-		Handler:      aws.String(d.Get("handler").(string)),
-		MemorySize:   aws.Int64(int64(d.Get("memory_size").(int))),
-		Role:         aws.String(iamRole),
-		Runtime:      aws.String(d.Get("runtime").(string)),
-		Timeout:      aws.Int64(int64(d.Get("timeout").(int))),
-		Publish:      aws.Bool(d.Get("publish").(bool)),
-	}
+	iamRole := d.Get("role").(string)
+	log.Printf("[DEBUG] Creating Serverless AWS Function %s with role %s", functionName, iamRole)
 
-	if v, ok := d.GetOk("layers"); ok && len(v.([]interface{})) > 0 {
-		funcParam.Layers = expandStringList(v.([]interface{}))
+	functionCode, release, err := expandFunctionCode(d, m.(*Config))
+	if err != nil {
+		return err
 	}
+	defer release()
 
-	if v, ok := d.GetOk("dead_letter_config"); ok {
-		dlcMaps := v.([]interface{})
-		if len(dlcMaps) == 1 { // Schema guarantees either 0 or 1
-			// Prevent panic on nil dead_letter_config.
-			if dlcMaps[0] == nil {
-				return fmt.Errorf("Nil dead_letter_config supplied for function: %s", functionName)
-			}
-			dlcMap := dlcMaps[0].(map[string]interface{})
-			funcParam.DeadLetterConfig = &lambda.DeadLetterConfig{
-				TargetArn: aws.String(dlcMap["target_arn"].(string)),
-			}
-		}
+	funcParam, err := expandCreateFunctionInput(d, functionCode)
+	if err != nil {
+		return err
 	}
 
-	if v, ok := d.GetOk("vpc_config"); ok && len(v.([]interface{})) > 0 {
-		config := v.([]interface{})[0].(map[string]interface{})
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
 
-		funcParam.VpcConfig = &lambda.VpcConfig{
-			SecurityGroupIds: expandStringSet(config["security_group_ids"].(*schema.Set)),
-			SubnetIds:        expandStringSet(config["subnet_ids"].(*schema.Set)),
-		}
+	input := s3CreateFunctionInput{
+		FunctionInput: funcParam,
+		Bucket:        event["bucket"].(string),
+		BucketOwner:   event["bucket_owner"].(string),
+		Events:        aws.StringValueSlice(expandStringSet(event["event_types"].(*schema.Set))),
+		Filter:        expandS3NotificationFilter(event),
 	}
 
-	if v, ok := d.GetOk("tracing_config"); ok {
-		tracingConfig := v.([]interface{})
-		tracing := tracingConfig[0].(map[string]interface{})
-		funcParam.TracingConfig = &lambda.TracingConfig{
-			Mode: aws.String(tracing["mode"].(string)),
-		}
+	if _, err := createFunctionWithRetry(conn, d, input, release); err != nil {
+		return err
 	}
 
-	if v, ok := d.GetOk("environment"); ok {
-		environments := v.([]interface{})
-		environment, ok := environments[0].(map[string]interface{})
-		if !ok {
-			return errors.New("At least one field is expected inside environment")
-		}
+	d.SetId(d.Get("function_name").(string))
 
-		if environmentVariables, ok := environment["variables"]; ok {
-			variables := readEnvironmentVariables(environmentVariables.(map[string]interface{}))
+	// if err := waitForLambdaFunctionCreation(conn, d.Id(), d.Timeout(schema.TimeoutCreate)); err != nil {
+	// 	return fmt.Errorf("error waiting for Lambda Function (%s) creation: %s", d.Id(), err)
+	// }
+
+	return resourceFunctionS3Read(d, m)
+
+}
 
-			funcParam.Environment = &lambda.Environment{
-				Variables: aws.StringMap(variables),
-			}
+func resourceFunctionS3Read(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+
+	auth.MakeClient(auth.Sess)
+
+	if err := readFunctionConfiguration(auth.Client.LambdaConn, d); err != nil {
+		if isAWSErr(err, lambda.ErrCodeResourceNotFoundException, "") && !d.IsNewResource() {
+			log.Printf("[WARN] Lambda function %s not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
 		}
+		return err
 	}
 
-	if v, ok := d.GetOk("kms_key_arn"); ok {
-		funcParam.KMSKeyArn = aws.String(v.(string))
+	if err := readFunctionVersions(auth.Client.LambdaConn, d); err != nil {
+		return err
 	}
 
-	if v, exists := d.GetOk("tags"); exists {
-		funcParam.Tags = tagsFromMapGeneric(v.(map[string]interface{}))
+	if err := readFunctionConcurrency(auth.Client.LambdaConn, d); err != nil {
+		return err
 	}
 
-	event := d.Get("event").([]interface{})[0].(map[string]interface{})
-
-	input := function.S3CreateFunctionInput{
-		FunctionInput: funcParam,
-		S3CreateEvent: function.S3CreateEvent{
-			Bucket: aws.String(event["bucket"].(string)),
-			Prefix: aws.String(event["object_prefix"].(string)),
-			Suffix: aws.String(event["object_suffix"].(string)),
-			Types:  expandStringSet(event["event_types"].(*schema.Set)),
-			Key:    aws.String(event["event_key"].(string)),
-		},
+	if err := readFunctionAsyncConfig(auth.Client.LambdaConn, d); err != nil {
+		return err
 	}
 
-	err := resource.Retry(1*time.Minute, func() *resource.RetryError {
-		_, err := function.CreateFunction(input)
-		log.Println(err) //ToDo
-
-		if err != nil {
-			log.Printf("[DEBUG] Error creating Lambda Function: %s", err)
-
-			if isAWSErr(err, "InvalidParameterValueException", "The role defined for the function cannot be assumed by Lambda") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", err)
-				return resource.RetryableError(err)
-			}
-			if isAWSErr(err, "InvalidParameterValueException", "The provided execution role does not have permissions") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", err)
-				return resource.RetryableError(err)
-			}
-			if isAWSErr(err, "InvalidParameterValueException", "Your request has been throttled by EC2") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", err)
-				return resource.RetryableError(err)
-			}
-			if isAWSErr(err, "InvalidParameterValueException", "Lambda was unable to configure access to your environment variables because the KMS key is invalid for CreateGrant") {
-				log.Printf("[DEBUG] Received %s, retrying CreateFunction", err)
-				return resource.RetryableError(err)
-			}
-			return resource.NonRetryableError(err)
-		}
-		return nil
-	})
+	if err := readFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
 
-	if err != nil {
-		// 	if !isResourceTimeoutError(err) && !isAWSErr(err, "InvalidParameterValueException", "Your request has been throttled by EC2") {
-		// 		return fmt.Errorf("Error creating Lambda function: %s", err)
-		// 	}
-		// 	// Allow additional time for slower uploads or EC2 throttling
-		// 	err := resource.Retry(d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
-		// 		_, err := conn.CreateFunction(params)
-		// 		if err != nil {
-		// 			log.Printf("[DEBUG] Error creating Lambda Function: %s", err)
-
-		// 			if isAWSErr(err, "InvalidParameterValueException", "Your request has been throttled by EC2") {
-		// 				log.Printf("[DEBUG] Received %s, retrying CreateFunction", err)
-		// 				return resource.RetryableError(err)
-		// 			}
-
-		// 			return resource.NonRetryableError(err)
-		// 		}
-		// 		return nil
-		// 	})
-		// 	if isResourceTimeoutError(err) {
-		// 		_, err = conn.CreateFunction(params)
-		// 	}
-		// 	if err != nil {
-		// 		return fmt.Errorf("Error creating Lambda function: %s", err)
-		// 	}
+	if err := readS3FunctionNotification(auth.Client.S3Conn, d); err != nil {
+		return err
 	}
 
-	d.SetId(d.Get("function_name").(string))
+	return nil
+}
 
-	// if err := waitForLambdaFunctionCreation(conn, d.Id(), d.Timeout(schema.TimeoutCreate)); err != nil {
-	// 	return fmt.Errorf("error waiting for Lambda Function (%s) creation: %s", d.Id(), err)
-	// }
+func resourceFunctionS3Update(d *schema.ResourceData, m interface{}) error {
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	conn := auth.Client.LambdaConn
 
-	return resourceFunctionS3Read(d, m)
+	if err := updateExecutionRole(auth.Client.IamConn, d, s3ExecutionRoleStatements(d)); err != nil {
+		return err
+	}
 
-}
+	if err := updateFunctionConfiguration(conn, d); err != nil {
+		return err
+	}
 
-func resourceFunctionS3Read(d *schema.ResourceData, m interface{}) error {
-	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	if err := deleteReplacedExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
 
-	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	if err := updateFunctionTags(conn, d); err != nil {
+		return err
+	}
 
-	input := function.S3ReadFunctionInput{
-		FunctionConfigurationInput: &lambda.GetFunctionConfigurationInput{
-			FunctionName: aws.String(d.Get("function_name").(string)),
-		},
-		S3ReadEvent: function.S3ReadEvent{
-			Bucket:      aws.String(event["bucket"].(string)),
-			StatementId: aws.String(event["statement_id"].(string)),
-		},
+	if err := updateFunctionCode(conn, d, m.(*Config)); err != nil {
+		return err
 	}
 
-	// qualifier for lambda function data source
-	// qualifier, qualifierExistance := d.GetOk("qualifier")
-	// if qualifierExistance {
-	// 	params.Qualifier = aws.String(qualifier.(string))
-	// 	log.Printf("[DEBUG] Fetching Lambda Function: %s:%s", d.Id(), qualifier.(string))
-	// } else {
-	// 	log.Printf("[DEBUG] Fetching Lambda Function: %s", d.Id())
-	// }
+	if err := updateFunctionAlias(conn, d); err != nil {
+		return err
+	}
 
-	functionOutput, err := function.ReadFunction(input)
-	if err != nil {
-		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ResourceNotFoundException" && !d.IsNewResource() {
-			d.SetId("")
-			return nil
-		}
+	if err := updateFunctionConcurrency(conn, d); err != nil {
 		return err
 	}
 
-	d.Set("arn", functionOutput["FunctionArn"])
-	d.Set("role", functionOutput["Role"])
-	d.Set("memory_size", functionOutput["MemorySize"])
-	d.Set("runtime", functionOutput["Runtime"])
-	d.Set("handler", functionOutput["Handler"])
-	d.Set("description", functionOutput["Description"])
-	d.Set("last_modified", functionOutput["LastModified"])
-	d.Set("timeout", functionOutput["Timeout"])
-	d.Set("source_code_hash", functionOutput["CodeSha256"])
-	d.Set("source_code_size", functionOutput["CodeSize"])
-
-	d.Set("event", []interface{}{
-		map[string]interface{}{
-			"bucket":       functionOutput["Bucket"],
-			"statement_id": functionOutput["StatementId"],
-			"event_types":  functionOutput["S3Events"],
-		},
-	})
+	if err := updateFunctionAsyncConfig(conn, d); err != nil {
+		return err
+	}
 
-	return nil
-}
+	if err := updateFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
+	if err := updateS3FunctionNotification(auth.Client.S3Conn, d); err != nil {
+		return err
+	}
 
-func resourceFunctionS3Update(d *schema.ResourceData, m interface{}) error {
 	return resourceFunctionS3Read(d, m)
 }
 
@@ -465,20 +253,29 @@ func resourceFunctionS3Delete(d *schema.ResourceData, m interface{}) error {
 	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
 
 	log.Printf("[INFO] Deleting Serverless Function: %s", d.Id())
-	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	auth.MakeClient(auth.Sess)
 
-	input := function.S3DeleteFunctionInput{
+	if err := deleteS3FunctionNotification(auth.Client.S3Conn, d); err != nil {
+		return err
+	}
+
+	input := triggerDeleteFunctionInput{
 		FunctionInput: &lambda.DeleteFunctionInput{
 			FunctionName: aws.String(d.Get("function_name").(string)),
 		},
-		S3DeleteEvent: function.S3DeleteEvent{
-			Bucket:      aws.String(event["bucket"].(string)),
-			StatementId: aws.String(event["statement_id"].(string)),
-		},
+		StatementId: s3FunctionStatementId(d),
 	}
 
 	function.DeleteFunction(input)
 
+	if err := deleteExecutionRole(auth.Client.IamConn, d); err != nil {
+		return err
+	}
+
+	if err := deleteFunctionLogGroup(cloudwatchlogs.New(auth.Sess), d); err != nil {
+		return err
+	}
+
 	// err := function.ReadFunction(input)
 	// if err != nil {
 	// 	return fmt.Errorf("Error deleting Serverless Function: %s", err)
@@ -486,3 +283,18 @@ func resourceFunctionS3Delete(d *schema.ResourceData, m interface{}) error {
 
 	return nil
 }
+
+// s3ExecutionRoleStatements allows a generated role to read the objects
+// that trigger the function
+func s3ExecutionRoleStatements(d *schema.ResourceData) []iamPolicyStatement {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	return []iamPolicyStatement{
+		{
+			Effect: "Allow",
+			Action: []string{"s3:GetObject"},
+			Resource: []string{
+				fmt.Sprintf("arn:%s:s3:::%s/%s*", currentPartition(), event["bucket"].(string), expandS3NotificationFilter(event).Prefix),
+			},
+		},
+	}
+}
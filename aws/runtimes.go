@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// runtimeLifecycle is the deprecation stage of a Lambda runtime
+type runtimeLifecycle int
+
+const (
+	// runtimeSupported runtimes can be used freely
+	runtimeSupported runtimeLifecycle = iota
+	// runtimeDeprecated runtimes still work but no longer get security patches
+	runtimeDeprecated
+	// runtimeCreateBlocked runtimes can no longer be used for new functions
+	runtimeCreateBlocked
+	// runtimeUpdateBlocked runtimes can no longer be used for new functions
+	// nor updated on existing ones
+	runtimeUpdateBlocked
+)
+
+// lambdaRuntime is an entry of the runtime catalog, Successor is the runtime
+// to migrate to once deprecated
+type lambdaRuntime struct {
+	Lifecycle runtimeLifecycle
+	Successor string
+}
+
+// lambdaRuntimeCatalog are the runtimes known to the provider
+var lambdaRuntimeCatalog = map[string]lambdaRuntime{
+	"dotnetcore1.0":   {Lifecycle: runtimeUpdateBlocked, Successor: "dotnet8"},
+	"dotnetcore2.0":   {Lifecycle: runtimeUpdateBlocked, Successor: "dotnet8"},
+	"dotnetcore2.1":   {Lifecycle: runtimeUpdateBlocked, Successor: "dotnet8"},
+	"dotnetcore3.1":   {Lifecycle: runtimeCreateBlocked, Successor: "dotnet8"},
+	"dotnet6":         {Lifecycle: runtimeDeprecated, Successor: "dotnet8"},
+	"dotnet8":         {Lifecycle: runtimeSupported},
+	"go1.x":           {Lifecycle: runtimeDeprecated, Successor: "provided.al2023"},
+	"java8":           {Lifecycle: runtimeDeprecated, Successor: "java8.al2"},
+	"java8.al2":       {Lifecycle: runtimeSupported},
+	"java11":          {Lifecycle: runtimeSupported},
+	"java17":          {Lifecycle: runtimeSupported},
+	"java21":          {Lifecycle: runtimeSupported},
+	"nodejs4.3":       {Lifecycle: runtimeUpdateBlocked, Successor: "nodejs22.x"},
+	"nodejs4.3-edge":  {Lifecycle: runtimeUpdateBlocked, Successor: "nodejs22.x"},
+	"nodejs6.10":      {Lifecycle: runtimeUpdateBlocked, Successor: "nodejs22.x"},
+	"nodejs8.10":      {Lifecycle: runtimeUpdateBlocked, Successor: "nodejs22.x"},
+	"nodejs10.x":      {Lifecycle: runtimeUpdateBlocked, Successor: "nodejs22.x"},
+	"nodejs12.x":      {Lifecycle: runtimeCreateBlocked, Successor: "nodejs22.x"},
+	"nodejs14.x":      {Lifecycle: runtimeCreateBlocked, Successor: "nodejs22.x"},
+	"nodejs16.x":      {Lifecycle: runtimeDeprecated, Successor: "nodejs22.x"},
+	"nodejs18.x":      {Lifecycle: runtimeDeprecated, Successor: "nodejs22.x"},
+	"nodejs20.x":      {Lifecycle: runtimeSupported},
+	"nodejs22.x":      {Lifecycle: runtimeSupported},
+	"provided":        {Lifecycle: runtimeDeprecated, Successor: "provided.al2023"},
+	"provided.al2":    {Lifecycle: runtimeSupported},
+	"provided.al2023": {Lifecycle: runtimeSupported},
+	"python2.7":       {Lifecycle: runtimeUpdateBlocked, Successor: "python3.13"},
+	"python3.6":       {Lifecycle: runtimeUpdateBlocked, Successor: "python3.13"},
+	"python3.7":       {Lifecycle: runtimeCreateBlocked, Successor: "python3.13"},
+	"python3.8":       {Lifecycle: runtimeDeprecated, Successor: "python3.13"},
+	"python3.9":       {Lifecycle: runtimeDeprecated, Successor: "python3.13"},
+	"python3.10":      {Lifecycle: runtimeSupported},
+	"python3.11":      {Lifecycle: runtimeSupported},
+	"python3.12":      {Lifecycle: runtimeSupported},
+	"python3.13":      {Lifecycle: runtimeSupported},
+	"ruby2.5":         {Lifecycle: runtimeUpdateBlocked, Successor: "ruby3.4"},
+	"ruby2.7":         {Lifecycle: runtimeCreateBlocked, Successor: "ruby3.4"},
+	"ruby3.2":         {Lifecycle: runtimeDeprecated, Successor: "ruby3.4"},
+	"ruby3.3":         {Lifecycle: runtimeSupported},
+	"ruby3.4":         {Lifecycle: runtimeSupported},
+}
+
+// validateRuntime warns about deprecated runtimes. Unknown and blocked
+// runtimes are rejected by customizeDiffRuntime, which knows whether
+// unknown runtimes are allowed and whether the function is being created.
+func validateRuntime(v interface{}, k string) (ws []string, errors []error) {
+	name := v.(string)
+	runtime, ok := lambdaRuntimeCatalog[name]
+	if ok && runtime.Lifecycle != runtimeSupported {
+		ws = append(ws, fmt.Sprintf("%q: runtime %s is deprecated, migrate to %s", k, name, runtime.Successor))
+	}
+	return
+}
+
+// checkRuntime returns an error if the runtime cannot be used to create
+// or update a function
+func checkRuntime(name string, creating, allowUnknown bool) error {
+	runtime, ok := lambdaRuntimeCatalog[name]
+	if !ok {
+		if allowUnknown {
+			return nil
+		}
+		return fmt.Errorf("runtime %s is unknown, set allow_unknown_runtimes in the provider to use it anyway", name)
+	}
+
+	switch {
+	case runtime.Lifecycle == runtimeUpdateBlocked:
+		return fmt.Errorf("runtime %s is deprecated and functions using it can no longer be created or updated, migrate to %s", name, runtime.Successor)
+	case runtime.Lifecycle == runtimeCreateBlocked && creating:
+		return fmt.Errorf("runtime %s is deprecated and can no longer be used to create functions, migrate to %s", name, runtime.Successor)
+	}
+	return nil
+}
+
+// customizeDiffRuntime rejects unknown runtimes and the runtimes blocked
+// for the planned operation
+func customizeDiffRuntime(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("runtime") || d.Get("runtime").(string) == "" {
+		return nil
+	}
+	if d.Id() != "" && len(d.GetChangedKeysPrefix("")) == 0 {
+		return nil
+	}
+
+	allowUnknown := false
+	if config, ok := m.(*Config); ok && config != nil {
+		allowUnknown = config.AllowUnknownRuntimes
+	}
+	return checkRuntime(d.Get("runtime").(string), d.Id() == "", allowUnknown)
+}
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestValidateRuntime(t *testing.T) {
+	testCases := []struct {
+		Runtime  string
+		Warnings int
+	}{
+		{Runtime: "nodejs22.x"},
+		{Runtime: "provided.al2023"},
+		{Runtime: "go1.x", Warnings: 1},
+		{Runtime: "python2.7", Warnings: 1},
+		{Runtime: "future1.0"},
+	}
+
+	for _, testCase := range testCases {
+		ws, errors := validateRuntime(testCase.Runtime, "runtime")
+		if len(errors) > 0 {
+			t.Errorf("%s: unexpected errors: %v", testCase.Runtime, errors)
+		}
+		if len(ws) != testCase.Warnings {
+			t.Errorf("%s: expected %d warnings, got %v", testCase.Runtime, testCase.Warnings, ws)
+		}
+	}
+}
+
+func TestCheckRuntime(t *testing.T) {
+	testCases := []struct {
+		Runtime      string
+		Creating     bool
+		AllowUnknown bool
+		Error        bool
+	}{
+		{Runtime: "python3.13", Creating: true},
+		{Runtime: "go1.x", Creating: true},
+		{Runtime: "nodejs14.x", Creating: true, Error: true},
+		{Runtime: "nodejs14.x"},
+		{Runtime: "python2.7", Creating: true, Error: true},
+		{Runtime: "python2.7", Error: true},
+		{Runtime: "future1.0", Creating: true, Error: true},
+		{Runtime: "future1.0", Creating: true, AllowUnknown: true},
+	}
+
+	for _, testCase := range testCases {
+		err := checkRuntime(testCase.Runtime, testCase.Creating, testCase.AllowUnknown)
+		if testCase.Error != (err != nil) {
+			t.Errorf("%s (creating %t, allow unknown %t): expected error %t, got %v", testCase.Runtime, testCase.Creating, testCase.AllowUnknown, testCase.Error, err)
+		}
+	}
+}
+
+func TestRuntimeCatalogSuccessors(t *testing.T) {
+	for name, runtime := range lambdaRuntimeCatalog {
+		if runtime.Lifecycle == runtimeSupported {
+			continue
+		}
+		successor, ok := lambdaRuntimeCatalog[runtime.Successor]
+		if !ok || successor.Lifecycle != runtimeSupported {
+			t.Errorf("%s: successor %q is not a supported runtime", name, runtime.Successor)
+		}
+	}
+}
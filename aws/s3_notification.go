@@ -0,0 +1,539 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// s3NotificationFilter selects the objects whose events invoke the function,
+// empty values match every key
+type s3NotificationFilter struct {
+	Prefix string
+	Suffix string
+}
+
+// expandS3NotificationFilter returns the filter of the event block, an
+// event_key is an exact key and matches as both prefix and suffix
+func expandS3NotificationFilter(event map[string]interface{}) s3NotificationFilter {
+	if key := event["event_key"].(string); key != "" {
+		return s3NotificationFilter{Prefix: key, Suffix: key}
+	}
+	return s3NotificationFilter{
+		Prefix: event["object_prefix"].(string),
+		Suffix: event["object_suffix"].(string),
+	}
+}
+
+// flattenS3NotificationFilter returns the prefix and suffix rules of a
+// notification filter
+func flattenS3NotificationFilter(filter *s3.NotificationConfigurationFilter) s3NotificationFilter {
+	f := s3NotificationFilter{}
+	if filter == nil || filter.Key == nil {
+		return f
+	}
+	for _, rule := range filter.Key.FilterRules {
+		switch strings.ToLower(aws.StringValue(rule.Name)) {
+		case "prefix":
+			f.Prefix = aws.StringValue(rule.Value)
+		case "suffix":
+			f.Suffix = aws.StringValue(rule.Value)
+		}
+	}
+	return f
+}
+
+// rules returns the filter rules of the notification, nil when every key matches
+func (f s3NotificationFilter) rules() *s3.NotificationConfigurationFilter {
+	rules := []*s3.FilterRule{}
+	if f.Prefix != "" {
+		rules = append(rules, &s3.FilterRule{Name: aws.String(s3.FilterRuleNamePrefix), Value: aws.String(f.Prefix)})
+	}
+	if f.Suffix != "" {
+		rules = append(rules, &s3.FilterRule{Name: aws.String(s3.FilterRuleNameSuffix), Value: aws.String(f.Suffix)})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &s3.NotificationConfigurationFilter{
+		Key: &s3.KeyFilter{FilterRules: rules},
+	}
+}
+
+// overlaps returns whether a key can match both filters. S3 rejects two
+// notifications of the same event type whose filters overlap, i.e. one
+// prefix starts with the other and one suffix ends with the other.
+func (f s3NotificationFilter) overlaps(other s3NotificationFilter) bool {
+	prefixes := strings.HasPrefix(f.Prefix, other.Prefix) || strings.HasPrefix(other.Prefix, f.Prefix)
+	suffixes := strings.HasSuffix(f.Suffix, other.Suffix) || strings.HasSuffix(other.Suffix, f.Suffix)
+	return prefixes && suffixes
+}
+
+// s3EventsOverlap returns the first event type of a matching an event type
+// of b, wildcards like s3:ObjectCreated:* match every event of their group
+func s3EventsOverlap(a, b []string) (string, bool) {
+	for _, x := range a {
+		for _, y := range b {
+			if s3EventMatches(x, y) || s3EventMatches(y, x) {
+				return x, true
+			}
+		}
+	}
+	return "", false
+}
+
+func s3EventMatches(pattern, event string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(event, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == event
+}
+
+// expectedBucketOwner returns the account expected to own the bucket, nil
+// when any owner is accepted
+func expectedBucketOwner(owner string) *string {
+	if owner == "" {
+		return nil
+	}
+	return aws.String(owner)
+}
+
+// readS3BucketRegion returns the region of the bucket
+func readS3BucketRegion(conn *s3.S3, bucket, owner string) (string, error) {
+	out, err := conn.GetBucketLocation(&s3.GetBucketLocationInput{
+		Bucket:              aws.String(bucket),
+		ExpectedBucketOwner: expectedBucketOwner(owner),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error reading location of bucket %s: %w", bucket, err)
+	}
+	return s3.NormalizeBucketLocation(aws.StringValue(out.LocationConstraint)), nil
+}
+
+// s3BucketDomainName returns the global domain name of the bucket in the
+// partition of the session
+func s3BucketDomainName(bucket string) string {
+	return bucket + ".s3." + partitionDNSSuffix(auth.Region)
+}
+
+// s3BucketRegionalDomainName returns the domain name of the bucket in its
+// region, us-east-1 buckets are served by the global endpoint
+func s3BucketRegionalDomainName(bucket, region string) string {
+	if region == "" || region == "us-east-1" {
+		return s3BucketDomainName(bucket)
+	}
+	return fmt.Sprintf("%s.s3.%s.%s", bucket, region, partitionDNSSuffix(region))
+}
+
+// s3BucketNotification is a notification of the bucket, of any destination
+type s3BucketNotification struct {
+	Id          string
+	Destination string
+	Events      []string
+	Filter      s3NotificationFilter
+}
+
+// s3BucketNotifications lists the lambda, queue and topic notifications of
+// the bucket configuration
+func s3BucketNotifications(config *s3.NotificationConfiguration) []s3BucketNotification {
+	notifications := []s3BucketNotification{}
+	for _, c := range config.LambdaFunctionConfigurations {
+		notifications = append(notifications, s3BucketNotification{
+			Id:          aws.StringValue(c.Id),
+			Destination: aws.StringValue(c.LambdaFunctionArn),
+			Events:      aws.StringValueSlice(c.Events),
+			Filter:      flattenS3NotificationFilter(c.Filter),
+		})
+	}
+	for _, c := range config.QueueConfigurations {
+		notifications = append(notifications, s3BucketNotification{
+			Id:          aws.StringValue(c.Id),
+			Destination: aws.StringValue(c.QueueArn),
+			Events:      aws.StringValueSlice(c.Events),
+			Filter:      flattenS3NotificationFilter(c.Filter),
+		})
+	}
+	for _, c := range config.TopicConfigurations {
+		notifications = append(notifications, s3BucketNotification{
+			Id:          aws.StringValue(c.Id),
+			Destination: aws.StringValue(c.TopicArn),
+			Events:      aws.StringValueSlice(c.Events),
+			Filter:      flattenS3NotificationFilter(c.Filter),
+		})
+	}
+	return notifications
+}
+
+// isFunctionNotification returns whether the destination is the function
+// or one of its versions and aliases
+func isFunctionNotification(destination, functionName string) bool {
+	parts := strings.Split(unqualifiedFunctionArn(destination), ":")
+	return len(parts) == 7 && parts[2] == "lambda" && parts[6] == functionName
+}
+
+// s3StatementId is the id of both the invoke permission and the bucket
+// notification of the function
+func s3StatementId(bucket, functionName string) string {
+	return "S3Event_" + bucket + "_" + functionName
+}
+
+// s3NotificationOwner returns a matcher of the notifications managed under
+// the statement id. Notifications created before they carried the statement
+// id are recognized by their function instead.
+func s3NotificationOwner(config *s3.NotificationConfiguration, statementId, functionName string) func(id, destination string) bool {
+	for _, c := range config.LambdaFunctionConfigurations {
+		if aws.StringValue(c.Id) == statementId {
+			return func(id, destination string) bool {
+				return id == statementId
+			}
+		}
+	}
+	return func(id, destination string) bool {
+		return isFunctionNotification(destination, functionName)
+	}
+}
+
+// longest returns the longest of two strings
+func longest(a, b string) string {
+	if len(a) > len(b) {
+		return a
+	}
+	return b
+}
+
+// checkS3NotificationOverlap returns an error if the notification managed
+// under the statement id overlaps another notification of the bucket
+func checkS3NotificationOverlap(config *s3.NotificationConfiguration, bucket, statementId, functionName string, events []string, filter s3NotificationFilter) error {
+	owns := s3NotificationOwner(config, statementId, functionName)
+	for _, n := range s3BucketNotifications(config) {
+		if owns(n.Id, n.Destination) {
+			continue
+		}
+		event, ok := s3EventsOverlap(events, n.Events)
+		if !ok || !filter.overlaps(n.Filter) {
+			continue
+		}
+		return fmt.Errorf("Notification %s overlaps notification %q of bucket %s to %s: both match %s on keys with prefix %q and suffix %q, "+
+			"S3 requires notifications of the same event type to have prefix or suffix rules that do not overlap",
+			statementId, n.Id, bucket, n.Destination, event, longest(filter.Prefix, n.Filter.Prefix), longest(filter.Suffix, n.Filter.Suffix))
+	}
+	return nil
+}
+
+// customizeDiffS3Notification checks at plan time that the notification
+// does not overlap the notifications already configured on the bucket
+func customizeDiffS3Notification(d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("event") || !d.NewValueKnown("event") || !d.NewValueKnown("function_name") {
+		return nil
+	}
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	bucket := event["bucket"].(string)
+
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	out, err := auth.Client.S3Conn.GetBucketNotificationConfiguration(&s3.GetBucketNotificationConfigurationRequest{
+		Bucket:              aws.String(bucket),
+		ExpectedBucketOwner: expectedBucketOwner(event["bucket_owner"].(string)),
+	})
+	if err != nil {
+		if isAWSErr(err, "NoSuchBucket", "") {
+			// The bucket may be created in the same apply
+			return nil
+		}
+		return fmt.Errorf("Error reading notification configuration of bucket %s: %s", bucket, err)
+	}
+
+	functionName := d.Get("function_name").(string)
+	return checkS3NotificationOverlap(out, bucket, s3StatementId(bucket, functionName), functionName,
+		aws.StringValueSlice(expandStringSet(event["event_types"].(*schema.Set))), expandS3NotificationFilter(event))
+}
+
+// checkS3BucketRegion returns an error if the bucket is not in the region
+// of the function, S3 only notifies functions of its own region
+func checkS3BucketRegion(bucket, bucketRegion, functionRegion string) error {
+	if bucketRegion == functionRegion {
+		return nil
+	}
+	return fmt.Errorf("Bucket %s is in region %s but the function is created in %s, S3 only notifies functions of the bucket region. "+
+		"Set allow_cross_region if the bucket is replicated to %s and the notification is expected to fail over", bucket, bucketRegion, functionRegion, functionRegion)
+}
+
+// customizeDiffS3BucketRegion checks at plan time that the bucket is in the
+// region of the function
+func customizeDiffS3BucketRegion(d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("event") || !d.NewValueKnown("event") {
+		return nil
+	}
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	if event["allow_cross_region"].(bool) {
+		return nil
+	}
+	bucket := event["bucket"].(string)
+
+	auth.StartSessionWithShared("eu-west-1", "default") //ToDo
+	auth.MakeClient(auth.Sess)
+	out, err := auth.Client.S3Conn.GetBucketLocation(&s3.GetBucketLocationInput{
+		Bucket:              aws.String(bucket),
+		ExpectedBucketOwner: expectedBucketOwner(event["bucket_owner"].(string)),
+	})
+	if err != nil {
+		if isAWSErr(err, "NoSuchBucket", "") {
+			// The bucket may be created in the same apply
+			return nil
+		}
+		return fmt.Errorf("Error reading location of bucket %s: %s", bucket, err)
+	}
+
+	region := s3.NormalizeBucketLocation(aws.StringValue(out.LocationConstraint))
+	return checkS3BucketRegion(bucket, region, auth.Region)
+}
+
+// s3FunctionNotification is the notification of the bucket invoking the
+// function, created and rolled back along with the function dependencies.
+// It is identified in the bucket configuration by the statement id.
+type s3FunctionNotification struct {
+	Bucket      string
+	BucketOwner string
+	StatementId string
+	FunctionArn string
+	Events      []string
+	Filter      s3NotificationFilter
+}
+
+// Create puts the notification in the bucket configuration
+func (n *s3FunctionNotification) Create() error {
+	auth.MakeClient(auth.Sess)
+	return putS3FunctionNotification(auth.Client.S3Conn, n)
+}
+
+// Delete removes the notification from the bucket configuration
+func (n *s3FunctionNotification) Delete() error {
+	auth.MakeClient(auth.Sess)
+	return putS3FunctionNotification(auth.Client.S3Conn, &s3FunctionNotification{
+		Bucket:      n.Bucket,
+		BucketOwner: n.BucketOwner,
+		StatementId: n.StatementId,
+		FunctionArn: n.FunctionArn,
+	})
+}
+
+// s3NotificationLockKey serializes the changes to the notification
+// configuration of a bucket, shared by every function it triggers
+func s3NotificationLockKey(bucket string) string {
+	return "s3-bucket-notification-" + bucket
+}
+
+// putS3FunctionNotification replaces the notification of the statement id
+// in the bucket configuration, keeping every other notification since S3
+// only accepts the configuration as a whole. A notification without events
+// is removed.
+func putS3FunctionNotification(conn *s3.S3, n *s3FunctionNotification) error {
+	parts := strings.Split(unqualifiedFunctionArn(n.FunctionArn), ":")
+	if len(parts) != 7 {
+		return fmt.Errorf("Error updating the notification of bucket %s: invalid function ARN %q", n.Bucket, n.FunctionArn)
+	}
+	functionName := parts[6]
+
+	lockKey := s3NotificationLockKey(n.Bucket)
+	awsMutexKV.Lock(lockKey)
+	defer awsMutexKV.Unlock(lockKey)
+
+	config, err := conn.GetBucketNotificationConfiguration(&s3.GetBucketNotificationConfigurationRequest{
+		Bucket:              aws.String(n.Bucket),
+		ExpectedBucketOwner: expectedBucketOwner(n.BucketOwner),
+	})
+	if err != nil {
+		if len(n.Events) == 0 && isAWSErr(err, "NoSuchBucket", "") {
+			return nil
+		}
+		return fmt.Errorf("Error reading notification configuration of bucket %s: %s", n.Bucket, err)
+	}
+	if err := checkS3NotificationOverlap(config, n.Bucket, n.StatementId, functionName, n.Events, n.Filter); err != nil {
+		return err
+	}
+
+	owns := s3NotificationOwner(config, n.StatementId, functionName)
+	functionConfigs := []*s3.LambdaFunctionConfiguration{}
+	for _, c := range config.LambdaFunctionConfigurations {
+		if !owns(aws.StringValue(c.Id), aws.StringValue(c.LambdaFunctionArn)) {
+			functionConfigs = append(functionConfigs, c)
+		}
+	}
+	if len(n.Events) > 0 {
+		functionConfigs = append(functionConfigs, &s3.LambdaFunctionConfiguration{
+			Id:                aws.String(n.StatementId),
+			LambdaFunctionArn: aws.String(n.FunctionArn),
+			Events:            aws.StringSlice(n.Events),
+			Filter:            n.Filter.rules(),
+		})
+	}
+	config.LambdaFunctionConfigurations = functionConfigs
+
+	log.Printf("[DEBUG] Putting notification configuration of bucket %s: %s", n.Bucket, config)
+	_, err = conn.PutBucketNotificationConfiguration(&s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    aws.String(n.Bucket),
+		ExpectedBucketOwner:       expectedBucketOwner(n.BucketOwner),
+		NotificationConfiguration: config,
+	})
+	if err != nil {
+		return fmt.Errorf("Error putting notification configuration of bucket %s: %s", n.Bucket, err)
+	}
+	return nil
+}
+
+// s3NotificationTarget returns the ARN invoked by the bucket, the alias
+// when triggers are bound to it
+func s3NotificationTarget(d *schema.ResourceData) string {
+	if d.Get("alias.0.bind_triggers").(bool) {
+		return d.Get("alias.0.arn").(string)
+	}
+	return d.Get("arn").(string)
+}
+
+// s3FunctionStatementId returns the statement id of the event block
+func s3FunctionStatementId(d *schema.ResourceData) string {
+	if v := d.Get("event.0.statement_id").(string); v != "" {
+		return v
+	}
+	return s3StatementId(d.Get("event.0.bucket").(string), d.Get("function_name").(string))
+}
+
+// updateS3FunctionNotification reconciles the bucket notification with the
+// event types and filters of the event block
+func updateS3FunctionNotification(conn *s3.S3, d *schema.ResourceData) error {
+	if !d.HasChange("event.0.event_types") && !d.HasChange("event.0.event_key") &&
+		!d.HasChange("event.0.object_prefix") && !d.HasChange("event.0.object_suffix") {
+		return nil
+	}
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+
+	return putS3FunctionNotification(conn, &s3FunctionNotification{
+		Bucket:      event["bucket"].(string),
+		BucketOwner: event["bucket_owner"].(string),
+		StatementId: s3FunctionStatementId(d),
+		FunctionArn: s3NotificationTarget(d),
+		Events:      aws.StringValueSlice(expandStringSet(event["event_types"].(*schema.Set))),
+		Filter:      expandS3NotificationFilter(event),
+	})
+}
+
+// readS3FunctionNotification refreshes the event block from the bucket
+// notification of the function, keeping the configured filters when they
+// still match, and resolves the domain names of the bucket
+func readS3FunctionNotification(conn *s3.S3, d *schema.ResourceData) error {
+	event := d.Get("event").([]interface{})[0].(map[string]interface{})
+	bucket := event["bucket"].(string)
+	owner := event["bucket_owner"].(string)
+	statementId := s3FunctionStatementId(d)
+
+	events := []string{}
+	filter := s3NotificationFilter{}
+	event["bucket_domain_name"] = s3BucketDomainName(bucket)
+	event["bucket_regional_domain_name"] = ""
+
+	region, err := readS3BucketRegion(conn, bucket, owner)
+	switch {
+	case isAWSErr(err, "NoSuchBucket", ""):
+		// The bucket is gone along with the notification
+		log.Printf("[WARN] Bucket %s not found", bucket)
+	case err != nil:
+		return err
+	default:
+		event["bucket_regional_domain_name"] = s3BucketRegionalDomainName(bucket, region)
+
+		out, err := conn.GetBucketNotificationConfiguration(&s3.GetBucketNotificationConfigurationRequest{
+			Bucket:              aws.String(bucket),
+			ExpectedBucketOwner: expectedBucketOwner(owner),
+		})
+		if err != nil {
+			return fmt.Errorf("Error reading notification configuration of bucket %s: %s", bucket, err)
+		}
+
+		owns := s3NotificationOwner(out, statementId, d.Id())
+		for _, c := range out.LambdaFunctionConfigurations {
+			if owns(aws.StringValue(c.Id), aws.StringValue(c.LambdaFunctionArn)) {
+				events = aws.StringValueSlice(c.Events)
+				filter = flattenS3NotificationFilter(c.Filter)
+				break
+			}
+		}
+		if len(events) == 0 {
+			log.Printf("[WARN] Notification %s of bucket %s not found", statementId, bucket)
+		}
+	}
+
+	event["statement_id"] = statementId
+
+	event["event_types"] = events
+	if key := event["event_key"].(string); key == "" || filter != (s3NotificationFilter{Prefix: key, Suffix: key}) {
+		event["event_key"] = ""
+		event["object_prefix"] = filter.Prefix
+		event["object_suffix"] = filter.Suffix
+	}
+
+	if err := d.Set("event", []interface{}{event}); err != nil {
+		return fmt.Errorf("Error setting event: %s", err)
+	}
+	return nil
+}
+
+// s3CreateFunctionInput creates the function invoked by the notifications
+// of a bucket, with the filters of the event block
+type s3CreateFunctionInput struct {
+	FunctionInput *lambda.CreateFunctionInput
+	Bucket        string
+	BucketOwner   string
+	Events        []string
+	Filter        s3NotificationFilter
+}
+
+// CreateDependencies allows the bucket to invoke the function and adds the
+// function notification to the bucket
+func (input s3CreateFunctionInput) CreateDependencies(lambdaResult *lambda.FunctionConfiguration) (map[string]interface{}, error) {
+	permission := functionPermission{
+		StatementId:   s3StatementId(input.Bucket, aws.StringValue(lambdaResult.FunctionName)),
+		FunctionName:  aws.StringValue(lambdaResult.FunctionArn),
+		SourceArn:     fmt.Sprintf("arn:%s:s3:::%s", currentPartition(), input.Bucket),
+		SourceAccount: input.BucketOwner,
+		Principal:     "s3.amazonaws.com",
+		Action:        "lambda:InvokeFunction",
+	}
+	notification := s3FunctionNotification{
+		Bucket:      input.Bucket,
+		BucketOwner: input.BucketOwner,
+		StatementId: permission.StatementId,
+		FunctionArn: aws.StringValue(lambdaResult.FunctionArn),
+		Events:      input.Events,
+		Filter:      input.Filter,
+	}
+	if err := executeCreate(&permission, &notification); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	out["Bucket"] = input.Bucket
+	out["StatementId"] = permission.StatementId
+	return out, nil
+}
+
+// GetFunctionInput returns the CreateFunctionInput of the function
+func (input s3CreateFunctionInput) GetFunctionInput() *lambda.CreateFunctionInput {
+	return input.FunctionInput
+}
+
+// deleteS3FunctionNotification removes the notification of the function
+// from the bucket, leaving the other notifications in place
+func deleteS3FunctionNotification(conn *s3.S3, d *schema.ResourceData) error {
+	return putS3FunctionNotification(conn, &s3FunctionNotification{
+		Bucket:      d.Get("event.0.bucket").(string),
+		BucketOwner: d.Get("event.0.bucket_owner").(string),
+		StatementId: s3FunctionStatementId(d),
+		FunctionArn: d.Get("arn").(string),
+	})
+}
@@ -0,0 +1,197 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestExpandS3NotificationFilter(t *testing.T) {
+	testCases := []struct {
+		Event    map[string]interface{}
+		Expected s3NotificationFilter
+	}{
+		{
+			Event:    map[string]interface{}{"event_key": "", "object_prefix": "", "object_suffix": ""},
+			Expected: s3NotificationFilter{},
+		},
+		{
+			Event:    map[string]interface{}{"event_key": "", "object_prefix": "uploads/", "object_suffix": ".jpg"},
+			Expected: s3NotificationFilter{Prefix: "uploads/", Suffix: ".jpg"},
+		},
+		{
+			Event:    map[string]interface{}{"event_key": "config/settings.json", "object_prefix": "", "object_suffix": ""},
+			Expected: s3NotificationFilter{Prefix: "config/settings.json", Suffix: "config/settings.json"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		filter := expandS3NotificationFilter(testCase.Event)
+		if filter != testCase.Expected {
+			t.Errorf("%v: expected %+v, got %+v", testCase.Event, testCase.Expected, filter)
+		}
+		if got := flattenS3NotificationFilter(filter.rules()); got != testCase.Expected {
+			t.Errorf("%v: expected %+v after flatten, got %+v", testCase.Event, testCase.Expected, got)
+		}
+	}
+}
+
+func TestS3NotificationFilterOverlaps(t *testing.T) {
+	testCases := []struct {
+		A, B     s3NotificationFilter
+		Overlaps bool
+	}{
+		{A: s3NotificationFilter{}, B: s3NotificationFilter{}, Overlaps: true},
+		{A: s3NotificationFilter{Prefix: "images/"}, B: s3NotificationFilter{}, Overlaps: true},
+		{A: s3NotificationFilter{Prefix: "images/"}, B: s3NotificationFilter{Prefix: "images/thumbs/"}, Overlaps: true},
+		{A: s3NotificationFilter{Prefix: "images/"}, B: s3NotificationFilter{Prefix: "videos/"}, Overlaps: false},
+		{A: s3NotificationFilter{Suffix: ".jpg"}, B: s3NotificationFilter{Suffix: ".png"}, Overlaps: false},
+		{A: s3NotificationFilter{Prefix: "images/", Suffix: ".jpg"}, B: s3NotificationFilter{Suffix: ".jpg"}, Overlaps: true},
+		{A: s3NotificationFilter{Prefix: "images/", Suffix: ".jpg"}, B: s3NotificationFilter{Prefix: "images/", Suffix: ".png"}, Overlaps: false},
+	}
+
+	for _, testCase := range testCases {
+		if got := testCase.A.overlaps(testCase.B); got != testCase.Overlaps {
+			t.Errorf("%+v and %+v: expected overlap %t, got %t", testCase.A, testCase.B, testCase.Overlaps, got)
+		}
+		if got := testCase.B.overlaps(testCase.A); got != testCase.Overlaps {
+			t.Errorf("%+v and %+v: expected overlap %t, got %t", testCase.B, testCase.A, testCase.Overlaps, got)
+		}
+	}
+}
+
+func TestS3EventsOverlap(t *testing.T) {
+	testCases := []struct {
+		A, B     []string
+		Overlaps bool
+	}{
+		{A: []string{"s3:ObjectCreated:Put"}, B: []string{"s3:ObjectCreated:Put"}, Overlaps: true},
+		{A: []string{"s3:ObjectCreated:*"}, B: []string{"s3:ObjectCreated:Put"}, Overlaps: true},
+		{A: []string{"s3:ObjectCreated:Copy"}, B: []string{"s3:ObjectCreated:*"}, Overlaps: true},
+		{A: []string{"s3:ObjectCreated:*"}, B: []string{"s3:ObjectRemoved:*"}, Overlaps: false},
+		{A: []string{"s3:ObjectCreated:Put"}, B: []string{"s3:ObjectCreated:Post"}, Overlaps: false},
+	}
+
+	for _, testCase := range testCases {
+		if _, got := s3EventsOverlap(testCase.A, testCase.B); got != testCase.Overlaps {
+			t.Errorf("%v and %v: expected overlap %t, got %t", testCase.A, testCase.B, testCase.Overlaps, got)
+		}
+	}
+}
+
+func TestCheckS3NotificationOverlap(t *testing.T) {
+	config := &s3.NotificationConfiguration{
+		LambdaFunctionConfigurations: []*s3.LambdaFunctionConfiguration{
+			{
+				Id:                aws.String("own"),
+				LambdaFunctionArn: aws.String("arn:aws:lambda:eu-west-1:123456789012:function:TestFunction:live"),
+				Events:            aws.StringSlice([]string{"s3:ObjectCreated:*"}),
+			},
+		},
+		QueueConfigurations: []*s3.QueueConfiguration{
+			{
+				Id:       aws.String("images"),
+				QueueArn: aws.String("arn:aws:sqs:eu-west-1:123456789012:images"),
+				Events:   aws.StringSlice([]string{"s3:ObjectCreated:Put"}),
+				Filter:   s3NotificationFilter{Prefix: "images/"}.rules(),
+			},
+		},
+	}
+
+	testCases := []struct {
+		Function string
+		Events   []string
+		Filter   s3NotificationFilter
+		Error    bool
+	}{
+		{Function: "TestFunction", Events: []string{"s3:ObjectCreated:*"}, Filter: s3NotificationFilter{Prefix: "videos/"}},
+		{Function: "TestFunction", Events: []string{"s3:ObjectRemoved:*"}, Filter: s3NotificationFilter{}},
+		{Function: "TestFunction", Events: []string{"s3:ObjectCreated:*"}, Filter: s3NotificationFilter{}, Error: true},
+		{Function: "OtherFunction", Events: []string{"s3:ObjectCreated:Post"}, Filter: s3NotificationFilter{}, Error: true},
+		{Function: "OtherFunction", Events: []string{"s3:ObjectRemoved:*"}, Filter: s3NotificationFilter{}},
+	}
+
+	for _, testCase := range testCases {
+		err := checkS3NotificationOverlap(config, "bucket", s3StatementId("bucket", testCase.Function), testCase.Function, testCase.Events, testCase.Filter)
+		if testCase.Error != (err != nil) {
+			t.Errorf("%s %v %+v: expected error %t, got %v", testCase.Function, testCase.Events, testCase.Filter, testCase.Error, err)
+		}
+	}
+}
+
+func TestS3NotificationOwner(t *testing.T) {
+	functionArn := "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction"
+	statementId := s3StatementId("bucket", "TestFunction")
+
+	legacy := &s3.NotificationConfiguration{
+		LambdaFunctionConfigurations: []*s3.LambdaFunctionConfiguration{
+			{Id: aws.String("generated"), LambdaFunctionArn: aws.String(functionArn)},
+		},
+	}
+	owns := s3NotificationOwner(legacy, statementId, "TestFunction")
+	if !owns("generated", functionArn) {
+		t.Errorf("expected the notification of the function to be owned before it carries the statement id")
+	}
+	if owns("other", "arn:aws:lambda:eu-west-1:123456789012:function:OtherFunction") {
+		t.Errorf("expected the notification of another function not to be owned")
+	}
+
+	tagged := &s3.NotificationConfiguration{
+		LambdaFunctionConfigurations: []*s3.LambdaFunctionConfiguration{
+			{Id: aws.String(statementId), LambdaFunctionArn: aws.String(functionArn)},
+			{Id: aws.String("manual"), LambdaFunctionArn: aws.String(functionArn)},
+		},
+	}
+	owns = s3NotificationOwner(tagged, statementId, "TestFunction")
+	if !owns(statementId, functionArn) {
+		t.Errorf("expected the notification %s to be owned", statementId)
+	}
+	if owns("manual", functionArn) {
+		t.Errorf("expected the notification added outside of the resource not to be owned")
+	}
+}
+
+func TestS3BucketRegionalDomainName(t *testing.T) {
+	testCases := []struct {
+		Region   string
+		Expected string
+	}{
+		{Region: "", Expected: "bucket.s3.amazonaws.com"},
+		{Region: "us-east-1", Expected: "bucket.s3.amazonaws.com"},
+		{Region: "eu-west-1", Expected: "bucket.s3.eu-west-1.amazonaws.com"},
+		{Region: "cn-north-1", Expected: "bucket.s3.cn-north-1.amazonaws.com.cn"},
+	}
+
+	for _, testCase := range testCases {
+		if got := s3BucketRegionalDomainName("bucket", testCase.Region); got != testCase.Expected {
+			t.Errorf("%q: expected %q, got %q", testCase.Region, testCase.Expected, got)
+		}
+	}
+}
+
+func TestCheckS3BucketRegion(t *testing.T) {
+	testCases := []struct {
+		BucketRegion string
+		Error        bool
+	}{
+		{BucketRegion: "eu-west-1"},
+		{BucketRegion: "us-east-1", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		err := checkS3BucketRegion("bucket", testCase.BucketRegion, "eu-west-1")
+		if testCase.Error != (err != nil) {
+			t.Errorf("%s: expected error %t, got %v", testCase.BucketRegion, testCase.Error, err)
+		}
+	}
+}
+
+func TestPutS3FunctionNotificationInvalidArn(t *testing.T) {
+	for _, functionArn := range []string{"", "TestFunction", "arn:aws:lambda:eu-west-1:123456789012"} {
+		err := putS3FunctionNotification(nil, &s3FunctionNotification{Bucket: "bucket", FunctionArn: functionArn})
+		if err == nil {
+			t.Errorf("%q: expected error", functionArn)
+		}
+	}
+}
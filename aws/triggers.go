@@ -0,0 +1,330 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/alessandromr/go-aws-serverless/utils/auth"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// lambdaPolicy is the resource based policy returned by lambda.GetPolicy
+type lambdaPolicy struct {
+	Statement []lambdaPolicyStatement
+}
+
+type lambdaPolicyStatement struct {
+	Sid       string
+	Effect    string
+	Principal json.RawMessage
+	Condition map[string]map[string]json.RawMessage
+}
+
+// service returns the service principal of the statement, if any
+func (s lambdaPolicyStatement) service() string {
+	var principal struct {
+		Service string
+	}
+	if err := json.Unmarshal(s.Principal, &principal); err != nil {
+		return ""
+	}
+	return principal.Service
+}
+
+// sourceArns returns the values of the AWS:SourceArn condition of the
+// statement, if any
+func (s lambdaPolicyStatement) sourceArns() []string {
+	var arns []string
+	for _, condition := range s.Condition {
+		for k, v := range condition {
+			if strings.EqualFold(k, "AWS:SourceArn") {
+				arns = append(arns, policyConditionValues(v)...)
+			}
+		}
+	}
+	return arns
+}
+
+// policyConditionValues decodes a condition value, either a single string
+// or a list of strings
+func policyConditionValues(raw json.RawMessage) []string {
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return []string{value}
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err == nil {
+		return values
+	}
+	return nil
+}
+
+// parseLambdaPolicy decodes the JSON policy document attached to a function
+func parseLambdaPolicy(document string) (*lambdaPolicy, error) {
+	policy := &lambdaPolicy{}
+	if err := json.Unmarshal([]byte(document), policy); err != nil {
+		return nil, fmt.Errorf("Error parsing Lambda policy: %s", err)
+	}
+	return policy, nil
+}
+
+// lambdaFunctionInvokeArn returns the ARN used by API Gateway integrations to
+// invoke the given function
+func lambdaFunctionInvokeArn(region, functionArn string) string {
+	return "arn:" + currentPartition() + ":apigateway:" + region + ":lambda:path/2015-03-31/functions/" + functionArn + "/invocations"
+}
+
+// functionArnFromIntegrationUri extracts the function ARN from an API Gateway
+// integration URI, returning an empty string for non Lambda integrations
+func functionArnFromIntegrationUri(uri string) string {
+	start := strings.Index(uri, ":lambda:path/2015-03-31/functions/")
+	if start < 0 {
+		return ""
+	}
+	arn := uri[start+len(":lambda:path/2015-03-31/functions/"):]
+	return strings.TrimSuffix(arn, "/invocations")
+}
+
+// unqualifiedFunctionArn strips the version or alias from a function ARN
+func unqualifiedFunctionArn(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) > 7 {
+		return strings.Join(parts[:7], ":")
+	}
+	return arn
+}
+
+// functionArnAccount returns the account id of a function ARN
+func functionArnAccount(functionArn string) (string, error) {
+	parts := strings.Split(functionArn, ":")
+	if len(parts) < 7 || parts[2] != "lambda" {
+		return "", fmt.Errorf("invalid function ARN %q", functionArn)
+	}
+	return parts[4], nil
+}
+
+// currentPartition returns the partition of the session region
+func currentPartition() string {
+	if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), auth.Region); ok {
+		return partition.ID()
+	}
+	return endpoints.AwsPartitionID
+}
+
+// partitionDNSSuffix returns the DNS suffix of the partition of the region
+func partitionDNSSuffix(region string) string {
+	if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return partition.DNSSuffix()
+	}
+	return "amazonaws.com"
+}
+
+// apiIdFromExecuteApiArn returns the rest api id of an execute-api ARN
+// (arn:partition:execute-api:region:account:api-id/stage/method/path)
+func apiIdFromExecuteApiArn(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 || parts[2] != "execute-api" {
+		return ""
+	}
+	return strings.SplitN(parts[5], "/", 2)[0]
+}
+
+// bucketFromS3Arn returns the bucket name of an S3 bucket ARN
+func bucketFromS3Arn(arn string) string {
+	prefix := "arn:" + currentPartition() + ":s3:::"
+	if !strings.HasPrefix(arn, prefix) {
+		return ""
+	}
+	return strings.SplitN(strings.TrimPrefix(arn, prefix), "/", 2)[0]
+}
+
+// readFunctionPolicyStatements returns the statements of the function policy,
+// an empty list is returned if the function has no policy attached
+func readFunctionPolicyStatements(conn *lambda.Lambda, functionName, qualifier string) ([]lambdaPolicyStatement, error) {
+	input := &lambda.GetPolicyInput{
+		FunctionName: aws.String(functionName),
+	}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+
+	out, err := conn.GetPolicy(input)
+	if err != nil {
+		if isAWSErr(err, "ResourceNotFoundException", "") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Error reading Lambda policy for %s: %s", functionName, err)
+	}
+
+	policy, err := parseLambdaPolicy(aws.StringValue(out.Policy))
+	if err != nil {
+		return nil, err
+	}
+	return policy.Statement, nil
+}
+
+// readFunctionS3Triggers returns the S3 notifications invoking the function
+// on every bucket allowed by the function policy
+func readFunctionS3Triggers(conn *s3.S3, functionArn string, statements []lambdaPolicyStatement) ([]interface{}, error) {
+	triggers := make([]interface{}, 0)
+	visited := make(map[string]bool)
+
+	for _, statement := range statements {
+		if statement.service() != "s3.amazonaws.com" {
+			continue
+		}
+		for _, sourceArn := range statement.sourceArns() {
+			bucket := bucketFromS3Arn(sourceArn)
+			if bucket == "" || visited[bucket] {
+				continue
+			}
+			visited[bucket] = true
+
+			log.Printf("[DEBUG] Reading notification configuration of bucket %s", bucket)
+			out, err := conn.GetBucketNotificationConfiguration(&s3.GetBucketNotificationConfigurationRequest{
+				Bucket: aws.String(bucket),
+			})
+			if err != nil {
+				if isAWSErr(err, "NoSuchBucket", "") {
+					continue
+				}
+				return nil, fmt.Errorf("Error reading notification configuration of bucket %s: %s", bucket, err)
+			}
+
+			for _, c := range out.LambdaFunctionConfigurations {
+				if unqualifiedFunctionArn(aws.StringValue(c.LambdaFunctionArn)) != unqualifiedFunctionArn(functionArn) {
+					continue
+				}
+				trigger := map[string]interface{}{
+					"bucket":          bucket,
+					"statement_id":    statement.Sid,
+					"notification_id": aws.StringValue(c.Id),
+					"function_arn":    aws.StringValue(c.LambdaFunctionArn),
+					"event_types":     aws.StringValueSlice(c.Events),
+				}
+				filter := flattenS3NotificationFilter(c.Filter)
+				trigger["object_prefix"] = filter.Prefix
+				trigger["object_suffix"] = filter.Suffix
+				triggers = append(triggers, trigger)
+			}
+		}
+	}
+
+	return triggers, nil
+}
+
+// readFunctionHTTPTriggers returns the API Gateway methods integrated with the
+// function on every rest api allowed by the function policy
+func readFunctionHTTPTriggers(conn *apigateway.APIGateway, functionArn string, statements []lambdaPolicyStatement) ([]interface{}, error) {
+	triggers := make([]interface{}, 0)
+	visited := make(map[string]bool)
+
+	for _, statement := range statements {
+		if statement.service() != "apigateway.amazonaws.com" {
+			continue
+		}
+		for _, sourceArn := range statement.sourceArns() {
+			apiID := apiIdFromExecuteApiArn(sourceArn)
+			if apiID == "" || visited[apiID] {
+				continue
+			}
+			visited[apiID] = true
+
+			bindings, err := readRestApiFunctionBindings(conn, apiID)
+			if err != nil {
+				if isAWSErr(err, "NotFoundException", "") {
+					continue
+				}
+				return nil, err
+			}
+
+			for _, binding := range bindings {
+				if unqualifiedFunctionArn(binding["function_arn"].(string)) != unqualifiedFunctionArn(functionArn) {
+					continue
+				}
+				binding["statement_id"] = statement.Sid
+				triggers = append(triggers, binding)
+			}
+		}
+	}
+
+	return triggers, nil
+}
+
+// readRestApiFunctionBindings returns every method of the rest api integrated
+// with a Lambda function
+func readRestApiFunctionBindings(conn *apigateway.APIGateway, apiID string) ([]map[string]interface{}, error) {
+	var resources []*apigateway.Resource
+	err := conn.GetResourcesPages(&apigateway.GetResourcesInput{
+		RestApiId: aws.String(apiID),
+		Embed:     aws.StringSlice([]string{"methods"}),
+	}, func(page *apigateway.GetResourcesOutput, lastPage bool) bool {
+		resources = append(resources, page.Items...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading resources of rest api %s: %s", apiID, err)
+	}
+
+	return flattenRestApiFunctionBindings(apiID, resources), nil
+}
+
+// flattenRestApiFunctionBindings returns the methods of the resources with a
+// Lambda integration, read from the methods embedded by GetResources
+func flattenRestApiFunctionBindings(apiID string, resources []*apigateway.Resource) []map[string]interface{} {
+	bindings := make([]map[string]interface{}, 0)
+	for _, resource := range resources {
+		for method, m := range resource.ResourceMethods {
+			if m == nil || m.MethodIntegration == nil {
+				continue
+			}
+			functionArn := functionArnFromIntegrationUri(aws.StringValue(m.MethodIntegration.Uri))
+			if functionArn == "" {
+				continue
+			}
+			bindings = append(bindings, map[string]interface{}{
+				"api_id":       apiID,
+				"resource_id":  aws.StringValue(resource.Id),
+				"path":         aws.StringValue(resource.Path),
+				"http_method":  method,
+				"function_arn": functionArn,
+			})
+		}
+	}
+
+	return bindings
+}
+
+// readFunctionEventSourceMappings returns the event source mappings of the
+// function, or of its version or alias when qualifier is set
+func readFunctionEventSourceMappings(conn *lambda.Lambda, functionName, qualifier string) ([]interface{}, error) {
+	if qualifier != "" {
+		functionName = functionName + ":" + qualifier
+	}
+
+	mappings := make([]interface{}, 0)
+	err := conn.ListEventSourceMappingsPages(&lambda.ListEventSourceMappingsInput{
+		FunctionName: aws.String(functionName),
+	}, func(page *lambda.ListEventSourceMappingsOutput, lastPage bool) bool {
+		for _, mapping := range page.EventSourceMappings {
+			mappings = append(mappings, map[string]interface{}{
+				"uuid":             aws.StringValue(mapping.UUID),
+				"event_source_arn": aws.StringValue(mapping.EventSourceArn),
+				"function_arn":     aws.StringValue(mapping.FunctionArn),
+				"state":            aws.StringValue(mapping.State),
+				"batch_size":       int(aws.Int64Value(mapping.BatchSize)),
+			})
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading event source mappings of %s: %s", functionName, err)
+	}
+	return mappings, nil
+}
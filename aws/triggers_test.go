@@ -0,0 +1,196 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+)
+
+func TestParseLambdaPolicy(t *testing.T) {
+	document := `{
+		"Version": "2012-10-17",
+		"Id": "default",
+		"Statement": [
+			{
+				"Sid": "S3Event_bucket_TestFunction",
+				"Effect": "Allow",
+				"Principal": {"Service": "s3.amazonaws.com"},
+				"Action": "lambda:InvokeFunction",
+				"Resource": "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction",
+				"Condition": {"ArnLike": {"AWS:SourceArn": "arn:aws:s3:::bucket"}}
+			},
+			{
+				"Sid": "ApiGateway",
+				"Effect": "Allow",
+				"Principal": {"Service": "apigateway.amazonaws.com"},
+				"Action": "lambda:InvokeFunction",
+				"Resource": "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction",
+				"Condition": {"ArnLike": {"AWS:SourceArn": [
+					"arn:aws:execute-api:eu-west-1:123456789012:abc123/*/*/test",
+					"arn:aws:execute-api:eu-west-1:123456789012:def456/*/*/test"
+				]}}
+			},
+			{
+				"Sid": "Everyone",
+				"Effect": "Allow",
+				"Principal": "*",
+				"Action": "lambda:InvokeFunction",
+				"Resource": "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction"
+			}
+		]
+	}`
+
+	policy, err := parseLambdaPolicy(document)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(policy.Statement) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(policy.Statement))
+	}
+	if got := policy.Statement[0].service(); got != "s3.amazonaws.com" {
+		t.Errorf("bad service: %q", got)
+	}
+	if got := policy.Statement[0].sourceArns(); len(got) != 1 || got[0] != "arn:aws:s3:::bucket" {
+		t.Errorf("bad source arns: %q", got)
+	}
+	if got := policy.Statement[1].sourceArns(); len(got) != 2 || apiIdFromExecuteApiArn(got[1]) != "def456" {
+		t.Errorf("bad source arns: %q", got)
+	}
+	if got := policy.Statement[2].service(); got != "" {
+		t.Errorf("expected no service, got %q", got)
+	}
+	if got := policy.Statement[2].sourceArns(); len(got) != 0 {
+		t.Errorf("expected no source arn, got %q", got)
+	}
+
+	if _, err := parseLambdaPolicy("not json"); err == nil {
+		t.Error("expected error parsing invalid policy")
+	}
+}
+
+func TestTriggerArnHelpers(t *testing.T) {
+	functionArn := "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction"
+
+	testCases := []struct {
+		Name     string
+		Got      string
+		Expected string
+	}{
+		{
+			Name:     "integration uri",
+			Got:      functionArnFromIntegrationUri(lambdaFunctionInvokeArn("eu-west-1", functionArn)),
+			Expected: functionArn,
+		},
+		{
+			Name:     "non lambda integration uri",
+			Got:      functionArnFromIntegrationUri("https://example.com/path"),
+			Expected: "",
+		},
+		{
+			Name:     "qualified function arn",
+			Got:      unqualifiedFunctionArn(functionArn + ":live"),
+			Expected: functionArn,
+		},
+		{
+			Name:     "unqualified function arn",
+			Got:      unqualifiedFunctionArn(functionArn),
+			Expected: functionArn,
+		},
+		{
+			Name:     "execute-api arn",
+			Got:      apiIdFromExecuteApiArn("arn:aws:execute-api:eu-west-1:123456789012:abc123/*/*/test"),
+			Expected: "abc123",
+		},
+		{
+			Name:     "non execute-api arn",
+			Got:      apiIdFromExecuteApiArn("arn:aws:s3:::bucket"),
+			Expected: "",
+		},
+		{
+			Name:     "bucket arn",
+			Got:      bucketFromS3Arn("arn:aws:s3:::bucket"),
+			Expected: "bucket",
+		},
+		{
+			Name:     "object arn",
+			Got:      bucketFromS3Arn("arn:aws:s3:::bucket/key"),
+			Expected: "bucket",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if testCase.Got != testCase.Expected {
+				t.Errorf("got %q, expected %q", testCase.Got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestFunctionArnAccount(t *testing.T) {
+	testCases := []struct {
+		FunctionArn string
+		Expected    string
+		Error       bool
+	}{
+		{FunctionArn: "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction", Expected: "123456789012"},
+		{FunctionArn: "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction:live", Expected: "123456789012"},
+		{FunctionArn: "", Error: true},
+		{FunctionArn: "arn:aws:lambda:eu-west-1", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		got, err := functionArnAccount(testCase.FunctionArn)
+		if testCase.Error != (err != nil) {
+			t.Errorf("%q: expected error %t, got %v", testCase.FunctionArn, testCase.Error, err)
+		}
+		if got != testCase.Expected {
+			t.Errorf("%q: expected %q, got %q", testCase.FunctionArn, testCase.Expected, got)
+		}
+	}
+}
+
+func TestFlattenRestApiFunctionBindings(t *testing.T) {
+	functionArn := "arn:aws:lambda:eu-west-1:123456789012:function:TestFunction"
+	resources := []*apigateway.Resource{
+		{
+			Id:   aws.String("res1"),
+			Path: aws.String("/test"),
+			ResourceMethods: map[string]*apigateway.Method{
+				"GET": {
+					MethodIntegration: &apigateway.Integration{
+						Uri: aws.String(lambdaFunctionInvokeArn("eu-west-1", functionArn)),
+					},
+				},
+				"POST": {
+					MethodIntegration: &apigateway.Integration{
+						Uri: aws.String("https://example.com/path"),
+					},
+				},
+				"OPTIONS": {},
+			},
+		},
+		{
+			Id:   aws.String("root"),
+			Path: aws.String("/"),
+		},
+	}
+
+	bindings := flattenRestApiFunctionBindings("abc123", resources)
+	if len(bindings) != 1 {
+		t.Fatalf("expected 1 binding, got %d", len(bindings))
+	}
+	expected := map[string]interface{}{
+		"api_id":       "abc123",
+		"resource_id":  "res1",
+		"path":         "/test",
+		"http_method":  "GET",
+		"function_arn": functionArn,
+	}
+	for k, v := range expected {
+		if bindings[0][k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, bindings[0][k])
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	lambdaMinMemorySize  = 128
+	lambdaMaxMemorySize  = 3008
+	lambdaMemorySizeStep = 64
+	lambdaMaxTimeout     = 900
+)
+
+var (
+	functionNameRegexp        = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+	accountIdRegexp           = regexp.MustCompile(`^\d{12}$`)
+	roleArnRegexp             = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]{1,512}$`)
+	environmentVariableRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+	// Handlers per runtime family: binary for go, file.function for the
+	// interpreted runtimes, package.Class::method for java and
+	// Assembly::Namespace.Class::Method for .NET
+	goHandlerRegexp       = regexp.MustCompile(`^[\w./-]+$`)
+	scriptHandlerRegexp   = regexp.MustCompile(`^[\w./-]+\.[\w$]+$`)
+	javaHandlerRegexp     = regexp.MustCompile(`^[\w$]+(\.[\w$]+)*(::[\w$]+)?$`)
+	dotnetHandlerRegexp   = regexp.MustCompile(`^[\w.]+::[\w.]+::[\w]+$`)
+	runtimeHandlerFormats = map[string]struct {
+		Regexp *regexp.Regexp
+		Format string
+	}{
+		"go":     {goHandlerRegexp, "the name of the executable"},
+		"nodejs": {scriptHandlerRegexp, "file.function"},
+		"python": {scriptHandlerRegexp, "file.function"},
+		"ruby":   {scriptHandlerRegexp, "file.method"},
+		"java":   {javaHandlerRegexp, "package.Class::method"},
+		"dotnet": {dotnetHandlerRegexp, "Assembly::Namespace.Class::Method"},
+	}
+)
+
+// validateMemorySize checks that the memory is in the range Lambda accepts
+// and a multiple of 64 MB
+func validateMemorySize(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value < lambdaMinMemorySize || value > lambdaMaxMemorySize {
+		errors = append(errors, fmt.Errorf("%q must be between %d and %d MB, got %d", k, lambdaMinMemorySize, lambdaMaxMemorySize, value))
+		return
+	}
+	if value%lambdaMemorySizeStep != 0 {
+		errors = append(errors, fmt.Errorf("%q must be a multiple of %d MB, got %d", k, lambdaMemorySizeStep, value))
+	}
+	return
+}
+
+// validateTimeout checks that the timeout is in the range Lambda accepts
+func validateTimeout(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value < 1 || value > lambdaMaxTimeout {
+		errors = append(errors, fmt.Errorf("%q must be between 1 and %d seconds, got %d", k, lambdaMaxTimeout, value))
+	}
+	return
+}
+
+// validateFunctionName checks that the function name is made of letters,
+// numbers, hyphens and underscores
+func validateFunctionName(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if !functionNameRegexp.MatchString(value) {
+		errors = append(errors, fmt.Errorf("%q must be 1 to 64 letters, numbers, hyphens or underscores, got %q", k, value))
+	}
+	return
+}
+
+// validateRoleArn checks that the role is the ARN of an IAM role
+func validateRoleArn(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+	if !roleArnRegexp.MatchString(value) {
+		errors = append(errors, fmt.Errorf("%q must be an IAM role ARN like arn:aws:iam::123456789012:role/name, got %q", k, value))
+	}
+	return
+}
+
+// validateEnvironmentVariables checks the keys of the environment
+// variables, the AWS_ prefix is reserved by Lambda
+func validateEnvironmentVariables(v interface{}, k string) (ws []string, errors []error) {
+	for key := range v.(map[string]interface{}) {
+		if strings.HasPrefix(key, "AWS_") {
+			errors = append(errors, fmt.Errorf("%q: %q is reserved, keys must not start with AWS_", k, key))
+			continue
+		}
+		if !environmentVariableRegexp.MatchString(key) {
+			errors = append(errors, fmt.Errorf("%q: %q must start with a letter and contain only letters, numbers and underscores", k, key))
+		}
+	}
+	return
+}
+
+// validateHandler checks the handler format of the runtime, custom
+// runtimes accept any handler
+func validateHandler(runtime, handler string) error {
+	for family, format := range runtimeHandlerFormats {
+		if !strings.HasPrefix(runtime, family) {
+			continue
+		}
+		if !format.Regexp.MatchString(handler) {
+			return fmt.Errorf("handler %q is not valid for runtime %s, expected %s", handler, runtime, format.Format)
+		}
+		return nil
+	}
+	return nil
+}
+
+// customizeDiffHandler checks the handler against the runtime
+func customizeDiffHandler(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("handler") || !d.NewValueKnown("runtime") {
+		return nil
+	}
+	return validateHandler(d.Get("runtime").(string), d.Get("handler").(string))
+}
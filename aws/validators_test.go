@@ -0,0 +1,154 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestValidateMemorySize(t *testing.T) {
+	testCases := []struct {
+		Value int
+		Error bool
+	}{
+		{Value: 128},
+		{Value: 1024},
+		{Value: 3008},
+		{Value: 64, Error: true},
+		{Value: 3072, Error: true},
+		{Value: 200, Error: true},
+	}
+
+	for _, testCase := range testCases {
+		_, errors := validateMemorySize(testCase.Value, "memory_size")
+		if testCase.Error != (len(errors) > 0) {
+			t.Errorf("%d: expected error %t, got %v", testCase.Value, testCase.Error, errors)
+		}
+	}
+}
+
+func TestValidateTimeout(t *testing.T) {
+	testCases := []struct {
+		Value int
+		Error bool
+	}{
+		{Value: 1},
+		{Value: 900},
+		{Value: 0, Error: true},
+		{Value: 901, Error: true},
+	}
+
+	for _, testCase := range testCases {
+		_, errors := validateTimeout(testCase.Value, "timeout")
+		if testCase.Error != (len(errors) > 0) {
+			t.Errorf("%d: expected error %t, got %v", testCase.Value, testCase.Error, errors)
+		}
+	}
+}
+
+func TestValidateFunctionName(t *testing.T) {
+	testCases := []struct {
+		Value string
+		Error bool
+	}{
+		{Value: "TestFunction"},
+		{Value: "test-function_1"},
+		{Value: "", Error: true},
+		{Value: "test.function", Error: true},
+		{Value: "test function", Error: true},
+		{Value: "arn:aws:lambda:eu-west-1:123456789012:function:test", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		_, errors := validateFunctionName(testCase.Value, "function_name")
+		if testCase.Error != (len(errors) > 0) {
+			t.Errorf("%q: expected error %t, got %v", testCase.Value, testCase.Error, errors)
+		}
+	}
+}
+
+func TestValidateRoleArn(t *testing.T) {
+	testCases := []struct {
+		Value string
+		Error bool
+	}{
+		{Value: ""},
+		{Value: "arn:aws:iam::123456789012:role/LambdaTestRole"},
+		{Value: "arn:aws:iam::123456789012:role/service-role/lambda@test"},
+		{Value: "arn:aws-cn:iam::123456789012:role/LambdaTestRole"},
+		{Value: "LambdaTestRole", Error: true},
+		{Value: "arn:aws:iam::123456789012:user/LambdaTestRole", Error: true},
+		{Value: "arn:aws:iam::1234:role/LambdaTestRole", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		_, errors := validateRoleArn(testCase.Value, "role")
+		if testCase.Error != (len(errors) > 0) {
+			t.Errorf("%q: expected error %t, got %v", testCase.Value, testCase.Error, errors)
+		}
+	}
+}
+
+func TestValidateEnvironmentVariables(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Value map[string]interface{}
+		Error bool
+	}{
+		{
+			Name:  "valid",
+			Value: map[string]interface{}{"TABLE_NAME": "test", "logLevel": "debug"},
+		},
+		{
+			Name:  "reserved",
+			Value: map[string]interface{}{"AWS_REGION": "eu-west-1"},
+			Error: true,
+		},
+		{
+			Name:  "invalid characters",
+			Value: map[string]interface{}{"TABLE-NAME": "test"},
+			Error: true,
+		},
+		{
+			Name:  "leading digit",
+			Value: map[string]interface{}{"1TABLE": "test"},
+			Error: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		_, errors := validateEnvironmentVariables(testCase.Value, "variables")
+		if testCase.Error != (len(errors) > 0) {
+			t.Errorf("%s: expected error %t, got %v", testCase.Name, testCase.Error, errors)
+		}
+	}
+}
+
+func TestValidateHandler(t *testing.T) {
+	testCases := []struct {
+		Runtime string
+		Handler string
+		Error   bool
+	}{
+		{Runtime: "go1.x", Handler: "main"},
+		{Runtime: "go1.x", Handler: "bin/handler"},
+		{Runtime: "go1.x", Handler: "main handler", Error: true},
+		{Runtime: "nodejs12.x", Handler: "index.handler"},
+		{Runtime: "nodejs12.x", Handler: "src/index.handler"},
+		{Runtime: "nodejs12.x", Handler: "index", Error: true},
+		{Runtime: "python3.8", Handler: "app.lambda_handler"},
+		{Runtime: "python3.8", Handler: "app:lambda_handler", Error: true},
+		{Runtime: "ruby2.5", Handler: "function.handler"},
+		{Runtime: "java11", Handler: "example.Handler::handleRequest"},
+		{Runtime: "java11", Handler: "example.Handler"},
+		{Runtime: "java11", Handler: "example.Handler::", Error: true},
+		{Runtime: "dotnetcore2.1", Handler: "Assembly::Example.Function::Handler"},
+		{Runtime: "dotnetcore2.1", Handler: "Example.Function.Handler", Error: true},
+		{Runtime: "provided", Handler: "anything goes"},
+	}
+
+	for _, testCase := range testCases {
+		err := validateHandler(testCase.Runtime, testCase.Handler)
+		if testCase.Error != (err != nil) {
+			t.Errorf("%s %q: expected error %t, got %v", testCase.Runtime, testCase.Handler, testCase.Error, err)
+		}
+	}
+}
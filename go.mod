@@ -4,7 +4,7 @@ go 1.13
 
 require (
 	github.com/alessandromr/go-aws-serverless v0.0.2-0.20200222223115-973b8e2cd739
-	github.com/aws/aws-sdk-go v1.25.48
+	github.com/aws/aws-sdk-go v1.36.31
 	github.com/hashicorp/terraform-plugin-sdk v1.4.0
 	github.com/mitchellh/go-homedir v1.1.0
 )
@@ -3,13 +3,45 @@ package main
 import (
 	"github.com/alessandromr/terraform-provider-aws-serverless/aws"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
 func Provider() *schema.Provider {
 	return &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"artifact_bucket": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"artifact_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "serverless-artifacts/",
+			},
+			"max_concurrent_uploads": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"allow_unknown_runtimes": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
 		ResourcesMap: map[string]*schema.Resource{
-			"serverless_aws_function_s3":   aws.ResourceFunctionS3(),
-			"serverless_aws_function_http": aws.ResourceFunctionHTTP(),
+			"serverless_aws_function_s3":              aws.ResourceFunctionS3(),
+			"serverless_aws_function_http":            aws.ResourceFunctionHTTP(),
+			"serverless_aws_function_eventbridge":     aws.ResourceFunctionEventBridge(),
+			"serverless_aws_function_cloudwatch_logs": aws.ResourceFunctionCloudWatchLogs(),
+			"serverless_aws_function_alb":             aws.ResourceFunctionALB(),
+			"serverless_aws_function_cognito_trigger": aws.ResourceFunctionCognitoTrigger(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"serverless_aws_function": aws.DataSourceFunction(),
+			"serverless_aws_http_api": aws.DataSourceHTTPApi(),
 		},
+		ConfigureFunc: aws.ProviderConfigure,
 	}
 }